@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Amenity adalah katalog fasilitas/tag properti (carport, kolam renang, bebas banjir, dll)
+type Amenity struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"type:varchar(100);not null" binding:"required,min=2,max=100"`
+	Slug      string    `json:"slug" gorm:"type:varchar(100);uniqueIndex;not null" binding:"omitempty,max=100"` // Dipakai di filter ?amenities=pool,carport
+	Category  string    `json:"category" gorm:"type:varchar(50)" binding:"max=50"`                              // Optional: fasilitas, lingkungan, dll
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Key pengaturan aplikasi yang bisa diubah admin
+const (
+	SettingMetadataAllowlist = "upload.metadata_allowlist" // Tag EXIF yang tidak dibuang saat upload foto
+)
+
+// AppSetting menyimpan pengaturan aplikasi dalam bentuk key-value
+type AppSetting struct {
+	Key       string    `json:"key" gorm:"primaryKey;type:varchar(100)"`
+	Value     string    `json:"value" gorm:"type:text"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// Conversation thread percakapan antara calon pembeli dan pemilik listing, satu thread per
+// pembeli per listing. OwnerID disimpan supaya thread tetap ada walau listing-nya dihapus.
+type Conversation struct {
+	ID            uint   `json:"id" gorm:"primaryKey"`
+	PropertyID    uint   `json:"property_id" gorm:"not null;uniqueIndex:idx_conversation_property_buyer"`
+	PropertyTitle string `json:"property_title,omitempty" gorm:"->;-:migration"` // Diisi dari join ke properties saat dibaca
+	BuyerID       uint   `json:"buyer_id" gorm:"not null;uniqueIndex:idx_conversation_property_buyer;index"`
+	OwnerID       uint   `json:"owner_id" gorm:"not null;index"`
+
+	LastMessageAt      *time.Time `json:"last_message_at,omitempty" gorm:"index"`
+	LastMessagePreview string     `json:"last_message_preview" gorm:"type:varchar(200)"`
+	UnreadCount        int64      `json:"unread_count" gorm:"->;-:migration"` // Pesan dari lawan bicara yang belum dibaca user yang login
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// HasParticipant true kalau user adalah pembeli atau pemilik listing di thread ini
+func (c *Conversation) HasParticipant(userID uint) bool {
+	return userID != 0 && (c.BuyerID == userID || c.OwnerID == userID)
+}
+
+// Message satu pesan di thread. ReadAt diisi saat penerima membuka thread (read receipt).
+type Message struct {
+	ID             uint                `json:"id" gorm:"primaryKey"`
+	ConversationID uint                `json:"conversation_id" gorm:"not null;index"`
+	SenderID       uint                `json:"sender_id" gorm:"not null;index"`
+	Body           string              `json:"body" gorm:"type:text"`
+	Attachments    []MessageAttachment `json:"attachments"`
+	ReadAt         *time.Time          `json:"read_at,omitempty" gorm:"index"`
+	CreatedAt      time.Time           `json:"created_at"`
+}
+
+// MessageAttachment file lampiran pesan, diupload lewat /upload atau /upload/video lalu
+// dipasang dengan URL-nya. Data file disalin dari MediaAsset.
+type MessageAttachment struct {
+	ID           uint    `json:"id" gorm:"primaryKey"`
+	MessageID    uint    `json:"message_id" gorm:"not null;index"`
+	URL          string  `json:"url" gorm:"not null;index"`
+	ThumbnailURL string  `json:"thumbnail_url,omitempty"`
+	ContentType  string  `json:"content_type"`
+	Size         int64   `json:"size"`
+	Width        int     `json:"width,omitempty"`
+	Height       int     `json:"height,omitempty"`
+	Duration     float64 `json:"duration,omitempty"` // Khusus video (detik)
+}
+
+// ApplyAsset menyalin data file dari MediaAsset hasil upload
+func (a *MessageAttachment) ApplyAsset(asset *MediaAsset) {
+	a.URL = asset.URL
+	a.ThumbnailURL = asset.Renditions.URL(RenditionThumb)
+	a.ContentType = asset.ContentType
+	a.Size = asset.Size
+	a.Width = asset.Width
+	a.Height = asset.Height
+	a.Duration = asset.Duration
+}
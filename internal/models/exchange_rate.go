@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// ExchangeRate menyimpan kurs mata uang terhadap Rupiah, dikelola manual oleh admin
+type ExchangeRate struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	Currency   string    `json:"currency" gorm:"type:varchar(3);uniqueIndex;not null" binding:"required,iso4217"` // USD, SGD, dll
+	IDRPerUnit float64   `json:"idr_per_unit" gorm:"not null" binding:"required,gt=0"`                            // 1 unit Currency = sekian Rupiah
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Batas shortlist per user
+const (
+	MaxShortlists     = 50
+	MaxShortlistItems = 100
+)
+
+// Favorite listing yang disimpan pembeli, satu baris per user per listing
+type Favorite struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_favorite_user_property"`
+	PropertyID uint      `json:"property_id" gorm:"not null;uniqueIndex:idx_favorite_user_property;index"`
+	Property   *Property `json:"property,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Shortlist daftar listing bernama milik pembeli (contoh: "Opsi dekat kantor"), bisa dibagikan
+// lewat link rahasia tanpa login. ShareToken kosong berarti belum dibagikan.
+type Shortlist struct {
+	ID          uint            `json:"id" gorm:"primaryKey"`
+	UserID      uint            `json:"user_id" gorm:"not null;index"`
+	Name        string          `json:"name" gorm:"type:varchar(100);not null" binding:"required,max=100"`
+	Description string          `json:"description" gorm:"type:varchar(500)" binding:"max=500"`
+	ShareToken  *string         `json:"-" gorm:"type:varchar(64);uniqueIndex"`
+	ShareURL    string          `json:"share_url,omitempty" gorm:"-"`     // Diisi untuk pemilik shortlist kalau sudah dibagikan
+	ItemCount   int64           `json:"item_count" gorm:"->;-:migration"` // Diisi dari subquery saat dibaca
+	Items       []ShortlistItem `json:"items,omitempty"`                  // Hanya di halaman detail
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// ShortlistItem satu listing di shortlist, dengan catatan pribadi pembeli
+type ShortlistItem struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	ShortlistID uint      `json:"shortlist_id" gorm:"not null;uniqueIndex:idx_shortlist_item_property"`
+	PropertyID  uint      `json:"property_id" gorm:"not null;uniqueIndex:idx_shortlist_item_property;index"`
+	Property    *Property `json:"property,omitempty"`
+	Note        string    `json:"note" gorm:"type:varchar(500)"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Status lead di inbox pemilik listing
+const (
+	InquiryStatusNew       = "new"
+	InquiryStatusContacted = "contacted"
+	InquiryStatusQualified = "qualified"
+	InquiryStatusClosed    = "closed"
+)
+
+// Cara calon pembeli ingin dihubungi
+const (
+	ContactPhone    = "phone"
+	ContactWhatsApp = "whatsapp"
+	ContactEmail    = "email"
+)
+
+// Inquiry pertanyaan calon pembeli tentang listing, masuk ke inbox pemilik listing sebagai lead.
+// OwnerID disimpan terpisah supaya lead tetap ada di inbox walau listing-nya sudah dihapus.
+type Inquiry struct {
+	ID               uint       `json:"id" gorm:"primaryKey"`
+	PropertyID       uint       `json:"property_id" gorm:"index;not null"`
+	PropertyTitle    string     `json:"property_title,omitempty" gorm:"->;-:migration"` // Diisi dari join ke properties saat dibaca
+	OwnerID          uint       `json:"owner_id" gorm:"index;not null"`
+	Name             string     `json:"name" gorm:"type:varchar(100);not null"`
+	Phone            string     `json:"phone" gorm:"type:varchar(20);not null;index"` // Dinormalisasi, contoh: +6281234567890
+	Email            string     `json:"email,omitempty" gorm:"type:varchar(255)"`
+	Message          string     `json:"message" gorm:"type:text;not null"`
+	PreferredContact string     `json:"preferred_contact" gorm:"type:varchar(20);not null;default:whatsapp"` // phone, whatsapp, email
+	Status           string     `json:"status" gorm:"type:varchar(20);not null;default:new;index"`           // new, contacted, qualified, closed
+	ReadAt           *time.Time `json:"read_at,omitempty"`
+	Read             bool       `json:"read" gorm:"-"`
+	Notes            string     `json:"notes" gorm:"type:text"` // Catatan pemilik listing, tidak terlihat pembeli
+
+	// Untuk proteksi spam, tidak dikirim ke client
+	IPAddress string `json:"-" gorm:"type:varchar(45);index"`
+	UserAgent string `json:"-" gorm:"type:varchar(255)"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AfterFind mengisi flag read dari ReadAt
+func (i *Inquiry) AfterFind(tx *gorm.DB) error {
+	i.Read = i.ReadAt != nil
+	return nil
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Nama rendition standar foto
+const (
+	RenditionThumb = "thumb"
+	RenditionCard  = "card"
+	RenditionFull  = "full"
+
+	RenditionPoster = "poster" // Frame video ukuran asli, dipakai sebagai poster player
+)
+
+// Status MediaAsset
+const (
+	MediaStatusPending = "pending" // Signed upload sudah dibuat, file belum dikonfirmasi
+	MediaStatusReady   = "ready"   // File sudah diproses dan siap dipakai
+)
+
+// MediaAsset mencatat file yang diupload lewat /upload beserta pemilik dan rendition-nya,
+// supaya PropertyPhoto dan Property bisa mengambil ukuran kecil dari URL aslinya
+// dan file yang tidak pernah dipakai bisa dibersihkan oleh GC
+type MediaAsset struct {
+	ID           uint            `json:"id" gorm:"primaryKey"`
+	UserID       uint            `json:"user_id" gorm:"index"`
+	URL          string          `json:"url" gorm:"uniqueIndex;not null"`
+	StorageKey   string          `json:"-"`
+	OriginalName string          `json:"original_name"` // Nama file dari client
+	Status       string          `json:"status" gorm:"type:varchar(20);not null;default:ready;index"`
+	ContentType  string          `json:"content_type"`
+	Size         int64           `json:"size"`
+	Width        int             `json:"width"`
+	Height       int             `json:"height"`
+	Renditions   ImageRenditions `json:"renditions" gorm:"type:text"`
+	Watermarked  bool            `json:"watermarked"`                                    // URL dan rendition publik ber-watermark, foto asli bersih ada di OriginalKey
+	OriginalKey  string          `json:"-"`                                              // Key foto asli tanpa watermark di storage private; selama pending berisi file mentah upload langsung
+	PHash        int64           `json:"-" gorm:"column:phash;not null;default:0;index"` // Perceptual hash (dHash 64 bit) untuk deteksi duplikat, 0 kalau belum dihitung
+	Duration     float64         `json:"duration,omitempty"`                             // Durasi video (detik), 0 untuk foto
+
+	// Privasi: metadata EXIF/GPS, XMP, IPTC dibuang dari file asli sebelum disimpan
+	MetadataStripped bool       `json:"metadata_stripped"`
+	MetadataRemoved  StringList `json:"metadata_removed,omitempty" gorm:"type:text"` // Jenis metadata yang ditemukan lalu dibuang, contoh: exif, gps, xmp
+	MetadataKept     StringList `json:"metadata_kept,omitempty" gorm:"type:text"`    // Tag EXIF yang dipertahankan sesuai allowlist admin
+
+	// Status pemakaian: attached kalau URL dipakai listing/foto; yang tidak dipakai dihapus GC setelah grace period
+	Attached   bool       `json:"attached" gorm:"not null;default:false;index"`
+	DetachedAt *time.Time `json:"detached_at,omitempty"` // Terakhir kali berhenti dipakai
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IsVideo true kalau asset adalah video hasil /upload/video
+func (a *MediaAsset) IsVideo() bool {
+	return strings.HasPrefix(a.ContentType, "video/")
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Organization agensi/kantor properti. User yang tergabung memakai pengaturan watermark organisasinya.
+type Organization struct {
+	ID        uint              `json:"id" gorm:"primaryKey"`
+	Name      string            `json:"name" gorm:"type:varchar(150);not null" binding:"required,min=2,max=150"`
+	Watermark WatermarkSettings `json:"watermark" gorm:"embedded;embeddedPrefix:watermark_"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// WatermarkSettings watermark yang ditempel ke foto publik listing. Logo dipakai kalau ada, selain itu teks.
+type WatermarkSettings struct {
+	Enabled  bool    `json:"enabled"`
+	Text     string  `json:"text" gorm:"type:varchar(100)" binding:"max=100"`
+	LogoURL  string  `json:"logo_url"`                                                                                                                           // Diisi lewat endpoint upload logo
+	Position string  `json:"position" gorm:"type:varchar(20);default:bottom-right" binding:"omitempty,oneof=top-left top-right bottom-left bottom-right center"` // Posisi di foto
+	Opacity  float64 `json:"opacity" gorm:"default:0.5" binding:"omitempty,gt=0,lte=1"`                                                                          // 0-1
+	Scale    float64 `json:"scale" gorm:"default:0.25" binding:"omitempty,gte=0.05,lte=0.8"`                                                                     // Lebar watermark relatif terhadap lebar foto
+}
@@ -1,6 +1,21 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"project-zero/pkg/rupiah"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Periode harga untuk listing sewa (WTR)
+const (
+	PricePeriodDaily   = "daily"
+	PricePeriodMonthly = "monthly"
+	PricePeriodYearly  = "yearly"
+)
 
 type Property struct {
 	ID          uint   `json:"id" gorm:"primaryKey"`
@@ -10,6 +25,21 @@ type Property struct {
 	ListingType string `json:"listing_type" binding:"required,oneof=WTS WTR"` // WTS, WTR
 	Price       int64  `json:"price" binding:"required,gt=0"`
 
+	// Tampilan Harga
+	Currency        string           `json:"currency" gorm:"type:varchar(3);not null;default:IDR" binding:"omitempty,iso4217"` // Kode mata uang ISO 4217, default IDR
+	PriceNegotiable bool             `json:"price_negotiable"`                                                                 // "Harga nego"
+	PriceHidden     bool             `json:"price_hidden"`                                                                     // "Hubungi kami", harga tidak ditampilkan ke publik
+	ConvertedPrices map[string]int64 `json:"converted_prices,omitempty" gorm:"-"`                                              // Perkiraan harga dalam mata uang lain (?currencies=USD,SGD)
+	PriceFormatted  string           `json:"price_formatted,omitempty" gorm:"-"`                                               // Contoh: "Rp 1.500.000.000"
+	PriceWords      string           `json:"price_words,omitempty" gorm:"-"`                                                   // Terbilang, hanya kalau diminta (?terbilang=true)
+
+	// Syarat Sewa (khusus WTR)
+	PricePeriod       string     `json:"price_period,omitempty" gorm:"type:varchar(10)" binding:"omitempty,oneof=daily monthly yearly"` // Harga per hari/bulan/tahun
+	MinLeaseMonths    int        `json:"min_lease_months,omitempty" binding:"gte=0,lte=120"`                                            // Minimal sewa (bulan)
+	Deposit           int64      `json:"deposit,omitempty" binding:"gte=0"`                                                             // Uang jaminan
+	IncludedUtilities StringList `json:"included_utilities,omitempty" gorm:"type:text"`                                                 // listrik, air, internet, dll
+	MonthlyPrice      int64      `json:"monthly_price,omitempty" gorm:"index"`                                                          // Harga sewa dinormalisasi per bulan, dihitung server
+
 	// Detail Teknis
 	LandSize     int `json:"land_size" binding:"required,gt=0"`     // Luas Tanah (m2)
 	BuildingSize int `json:"building_size" binding:"required,gt=0"` // Luas Bangunan (m2)
@@ -23,9 +53,106 @@ type Property struct {
 	WaterSource string `json:"water_source"`                                               // PAM, Sumur - Optional
 	Address     string `json:"address" binding:"required,max=500"`                         // No min length
 
+	// Verifikasi Legal, diisi lewat alur verifikasi sertifikat oleh admin
+	VerificationStatus string     `json:"verification_status" gorm:"type:varchar(20);not null;default:none;index"` // none, pending, verified, rejected
+	LegalVerified      bool       `json:"legal_verified" gorm:"-"`                                                 // Badge "verified legal"
+	LegalVerifiedAt    *time.Time `json:"legal_verified_at,omitempty"`
+
+	// Amenities (many-to-many lewat tabel property_amenities)
+	Amenities  []Amenity `json:"amenities,omitempty" gorm:"many2many:property_amenities;"`
+	AmenityIDs []uint    `json:"amenity_ids,omitempty" gorm:"-"` // Input only: ID amenity yang dipasang ke property
+
+	// Riwayat Harga
+	OriginalPrice         int64      `json:"original_price"`             // Harga awal saat listing dibuat
+	PriceChangedAt        *time.Time `json:"price_changed_at,omitempty"` // Terakhir kali harga berubah
+	PriceReduced          bool       `json:"price_reduced" gorm:"-"`     // Dihitung: harga sekarang < harga awal
+	PriceReductionPercent float64    `json:"price_reduction_percent,omitempty" gorm:"-"`
+
 	// Media
-	PhotoPath string `json:"photo_path"` // Path foto properti
+	PhotoPath          string         `json:"photo_path"`                              // Path foto properti
+	PhotoThumbnailPath string         `json:"photo_thumbnail_path,omitempty" gorm:"-"` // Rendition "card" dari PhotoPath untuk halaman list
+	Media              *PropertyMedia `json:"media,omitempty" gorm:"-"`                // Foto, denah, video, 360 dan tour, hanya di halaman detail
+
+	// Favorit, dihitung per user yang login
+	IsFavorite    bool   `json:"is_favorite" gorm:"-"`
+	FavoriteCount *int64 `json:"favorite_count,omitempty" gorm:"-"` // Jumlah pembeli yang menyimpan listing, hanya untuk pemilik listing
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// ValidateRentalTerms memastikan syarat sewa sesuai ListingType:
+// WTR wajib punya price_period, WTS tidak boleh punya syarat sewa
+func (p *Property) ValidateRentalTerms() error {
+	if p.ListingType == "WTR" {
+		if p.PricePeriod == "" {
+			return errors.New("price_period wajib diisi untuk listing WTR (daily, monthly, yearly)")
+		}
+		return nil
+	}
+
+	if p.PricePeriod != "" || p.MinLeaseMonths > 0 || p.Deposit > 0 || len(p.IncludedUtilities) > 0 {
+		return errors.New("price_period, min_lease_months, deposit dan included_utilities hanya untuk listing WTR")
+	}
+	return nil
+}
+
+// NormalizeRentalPrice mengisi MonthlyPrice dari Price dan PricePeriod.
+// Listing WTS tidak punya harga bulanan (0).
+func (p *Property) NormalizeRentalPrice() {
+	switch {
+	case p.ListingType != "WTR":
+		p.MonthlyPrice = 0
+	case p.PricePeriod == PricePeriodDaily:
+		p.MonthlyPrice = p.Price * 30
+	case p.PricePeriod == PricePeriodYearly:
+		p.MonthlyPrice = p.Price / 12
+	default:
+		p.MonthlyPrice = p.Price
+	}
+}
+
+// MaskHiddenPrice mengosongkan semua angka harga untuk listing "hubungi kami"
+func (p *Property) MaskHiddenPrice() {
+	if !p.PriceHidden {
+		return
+	}
+	p.Price = 0
+	p.MonthlyPrice = 0
+	p.OriginalPrice = 0
+	p.Deposit = 0
+	p.PriceReduced = false
+	p.PriceReductionPercent = 0
+	p.ConvertedPrices = nil
+	p.PriceFormatted = "Hubungi kami"
+	p.PriceWords = ""
+}
+
+// UnmarshalJSON menerima price dan deposit sebagai angka atau teks ("1,5 M", "750jt", "Rp 1.500.000")
+func (p *Property) UnmarshalJSON(data []byte) error {
+	type propertyAlias Property
+	aux := struct {
+		*propertyAlias
+		Price   rupiah.Amount `json:"price"`
+		Deposit rupiah.Amount `json:"deposit"`
+	}{propertyAlias: (*propertyAlias)(p)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	p.Price = int64(aux.Price)
+	p.Deposit = int64(aux.Deposit)
+	return nil
+}
+
+// AfterFind mengisi flag price_reduced dan persentase penurunan dari harga awal, serta badge legal_verified
+func (p *Property) AfterFind(tx *gorm.DB) error {
+	p.LegalVerified = p.VerificationStatus == VerificationVerified
+	p.PriceReduced = p.OriginalPrice > 0 && p.Price < p.OriginalPrice
+	p.PriceReductionPercent = 0
+	if p.PriceReduced {
+		pct := float64(p.OriginalPrice-p.Price) / float64(p.OriginalPrice) * 100
+		p.PriceReductionPercent = math.Round(pct*10) / 10
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Jenis dokumen legal listing
+const (
+	DocumentTypeCertificate = "certificate" // Sertifikat SHM/HGB/girik
+	DocumentTypeIMB         = "imb"         // Izin Mendirikan Bangunan
+	DocumentTypePBG         = "pbg"         // Persetujuan Bangunan Gedung (pengganti IMB)
+	DocumentTypePBB         = "pbb"         // Bukti bayar PBB
+	DocumentTypeAJB         = "ajb"         // Akta Jual Beli
+	DocumentTypeOther       = "other"
+)
+
+// DocumentExpiringWindow dokumen dianggap akan kadaluarsa kalau expires_at kurang dari jangka ini
+const DocumentExpiringWindow = 90 * 24 * time.Hour
+
+// PropertyDocument dokumen legal listing (sertifikat, IMB/PBG, PBB, dll). File disimpan di storage
+// private dan hanya bisa diunduh pemilik listing atau admin lewat link sementara.
+type PropertyDocument struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	PropertyID uint       `json:"property_id" gorm:"index;not null"`
+	UserID     uint       `json:"user_id" gorm:"index"` // Yang mengupload
+	Type       string     `json:"type" gorm:"type:varchar(20);not null;index" binding:"required,oneof=certificate imb pbg pbb ajb other"`
+	Title      string     `json:"title" gorm:"type:varchar(150)" binding:"max=150"`
+	Number     string     `json:"number" gorm:"type:varchar(100)" binding:"max=100"` // Nomor sertifikat/IMB/NOP PBB
+	IssuedAt   *time.Time `json:"issued_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" gorm:"index"` // Contoh: masa berlaku HGB
+	Notes      string     `json:"notes" gorm:"type:text" binding:"max=1000"`
+
+	// File
+	OriginalName string `json:"original_name"`
+	ContentType  string `json:"content_type"`
+	Size         int64  `json:"size"`
+	StorageKey   string `json:"-"` // Key di storage private, tidak pernah dikirim ke client
+
+	// Status masa berlaku, dihitung server
+	Expired      bool `json:"expired" gorm:"-"`
+	ExpiringSoon bool `json:"expiring_soon" gorm:"-"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ApplyExpiry mengisi Expired dan ExpiringSoon berdasarkan ExpiresAt
+func (d *PropertyDocument) ApplyExpiry(now time.Time) {
+	d.Expired, d.ExpiringSoon = false, false
+	if d.ExpiresAt == nil {
+		return
+	}
+	d.Expired = d.ExpiresAt.Before(now)
+	d.ExpiringSoon = !d.Expired && d.ExpiresAt.Before(now.Add(DocumentExpiringWindow))
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Status baris hasil import
+const (
+	ImportRowValid    = "valid"
+	ImportRowInvalid  = "invalid"
+	ImportRowImported = "imported"
+)
+
+// PropertyImport menyimpan hasil satu kali import listing dari CSV/XLSX,
+// supaya laporan per baris bisa didownload ulang
+type PropertyImport struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	UserID       uint       `json:"user_id" gorm:"index;not null"`
+	FileName     string     `json:"file_name" gorm:"type:varchar(255)"`
+	DryRun       bool       `json:"dry_run"`
+	TotalRows    int        `json:"total_rows"`
+	ValidRows    int        `json:"valid_rows"`
+	ImportedRows int        `json:"imported_rows"`
+	Rows         ImportRows `json:"rows,omitempty" gorm:"type:text"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// PropertyImportRow hasil validasi/import satu baris spreadsheet
+type PropertyImportRow struct {
+	Row        int      `json:"row"` // Nomor baris di file (header = baris 1)
+	Status     string   `json:"status"`
+	Title      string   `json:"title,omitempty"`
+	PropertyID uint     `json:"property_id,omitempty"`
+	Errors     []string `json:"errors,omitempty"`
+}
+
+// ImportRows disimpan sebagai JSON array di kolom text
+type ImportRows []PropertyImportRow
+
+func (r ImportRows) Value() (driver.Value, error) {
+	if r == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(r)
+	return string(b), err
+}
+
+func (r *ImportRows) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*r = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("ImportRows: tipe data tidak didukung %T", value)
+	}
+	return json.Unmarshal(data, r)
+}
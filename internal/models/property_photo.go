@@ -2,10 +2,86 @@ package models
 
 import "time"
 
+// Jenis media listing
+const (
+	MediaKindPhoto     = "photo"
+	MediaKindFloorPlan = "floor_plan" // Denah
+	MediaKindVideo     = "video"      // Video MP4/WebM hasil /upload/video
+	MediaKindPanorama  = "panorama"   // Foto 360 equirectangular (rasio 2:1)
+	MediaKindTour      = "tour"       // Link virtual tour eksternal (Matterport, YouTube, dll)
+)
+
+// PropertyPhoto satu media listing. Walau namanya foto, kind menentukan jenisnya: foto galeri,
+// denah, video, foto 360 atau link virtual tour. Untuk tour, PhotoPath berisi link eksternal.
 type PropertyPhoto struct {
-	ID         uint      `json:"id" gorm:"primaryKey"`
-	PropertyID uint      `json:"property_id" binding:"required"`
-	PhotoPath  string    `json:"photo_path" binding:"required"`
-	Caption    string    `json:"caption"`
-	CreatedAt  time.Time `json:"created_at"`
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	PropertyID uint   `json:"property_id" binding:"required"`
+	Kind       string `json:"kind" gorm:"type:varchar(20);not null;default:photo;index" binding:"omitempty,oneof=photo floor_plan video panorama tour"`
+	PhotoPath  string `json:"photo_path" binding:"required"`
+	Caption    string `json:"caption" binding:"max=500"`
+
+	// Urutan galeri dan foto utama, diatur lewat endpoint reorder dan cover
+	Position int  `json:"position" gorm:"index;not null;default:0"`
+	IsCover  bool `json:"is_cover" gorm:"not null;default:false"`
+
+	// Rendition hasil proses upload, diisi server dari MediaAsset. Untuk video, rendition
+	// dibuat dari poster frame.
+	Width        int             `json:"width,omitempty"`
+	Height       int             `json:"height,omitempty"`
+	ThumbnailURL string          `json:"thumbnail_url,omitempty"`
+	Renditions   ImageRenditions `json:"renditions,omitempty" gorm:"type:text"`
+	PosterURL    string          `json:"poster_url,omitempty"` // Khusus video
+	Duration     float64         `json:"duration,omitempty"`   // Durasi video (detik)
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ApplyAsset menyalin ukuran dan rendition dari MediaAsset hasil upload
+func (p *PropertyPhoto) ApplyAsset(asset *MediaAsset) {
+	if asset == nil {
+		p.Width, p.Height, p.ThumbnailURL, p.Renditions = 0, 0, "", nil
+		p.PosterURL, p.Duration = "", 0
+		return
+	}
+	p.Width = asset.Width
+	p.Height = asset.Height
+	p.Renditions = asset.Renditions
+	p.ThumbnailURL = asset.Renditions.URL(RenditionThumb)
+	p.PosterURL = asset.Renditions.URL(RenditionPoster)
+	p.Duration = asset.Duration
+}
+
+// PropertyMedia media listing dikelompokkan per jenis untuk halaman detail, masing-masing urut posisi galeri
+type PropertyMedia struct {
+	Photos     []PropertyPhoto `json:"photos"`
+	FloorPlans []PropertyPhoto `json:"floor_plans"`
+	Videos     []PropertyPhoto `json:"videos"`
+	Panoramas  []PropertyPhoto `json:"panoramas"`
+	Tours      []PropertyPhoto `json:"tours"`
+}
+
+// GroupMedia mengelompokkan media per kind, kind kosong (data lama) dianggap foto
+func GroupMedia(items []PropertyPhoto) *PropertyMedia {
+	media := &PropertyMedia{
+		Photos:     []PropertyPhoto{},
+		FloorPlans: []PropertyPhoto{},
+		Videos:     []PropertyPhoto{},
+		Panoramas:  []PropertyPhoto{},
+		Tours:      []PropertyPhoto{},
+	}
+	for _, item := range items {
+		switch item.Kind {
+		case MediaKindFloorPlan:
+			media.FloorPlans = append(media.FloorPlans, item)
+		case MediaKindVideo:
+			media.Videos = append(media.Videos, item)
+		case MediaKindPanorama:
+			media.Panoramas = append(media.Panoramas, item)
+		case MediaKindTour:
+			media.Tours = append(media.Tours, item)
+		default:
+			media.Photos = append(media.Photos, item)
+		}
+	}
+	return media
 }
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// PropertyPriceHistory mencatat setiap perubahan harga listing
+type PropertyPriceHistory struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	PropertyID uint      `json:"property_id" gorm:"index;not null"`
+	OldPrice   int64     `json:"old_price"` // 0 untuk harga awal saat listing dibuat
+	NewPrice   int64     `json:"new_price"`
+	ChangedAt  time.Time `json:"changed_at" gorm:"index"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Status verifikasi legal di listing
+const (
+	VerificationNone     = "none"     // Belum pernah diajukan, atau direset karena sertifikat/dokumen verifikasi diubah
+	VerificationPending  = "pending"  // Menunggu review admin
+	VerificationVerified = "verified" // Disetujui, listing dapat badge "verified legal"
+	VerificationRejected = "rejected"
+)
+
+// Status pengajuan verifikasi
+const (
+	VerificationRequestPending   = "pending"
+	VerificationRequestApproved  = "approved"
+	VerificationRequestRejected  = "rejected"
+	VerificationRequestCancelled = "cancelled" // Diganti pengajuan baru, atau sertifikat/dokumen verifikasi diubah
+)
+
+// PropertyVerification pengajuan verifikasi sertifikat listing oleh pemilik. Admin mereview
+// dokumen yang dilampirkan lalu menyetujui atau menolak dengan catatan.
+type PropertyVerification struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	PropertyID  uint   `json:"property_id" gorm:"index;not null"`
+	UserID      uint   `json:"user_id" gorm:"index"`                          // Yang mengajukan
+	Certificate string `json:"certificate" gorm:"type:varchar(20);not null"`  // Sertifikat listing saat diajukan
+	Status      string `json:"status" gorm:"type:varchar(20);not null;index"` // pending, approved, rejected, cancelled
+	Notes       string `json:"notes" gorm:"type:text"`                        // Catatan dari pemilik
+
+	// Review admin
+	ReviewerID  *uint      `json:"reviewer_id,omitempty"`
+	ReviewNotes string     `json:"review_notes" gorm:"type:text"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
+
+	Documents []PropertyDocument `json:"documents,omitempty" gorm:"many2many:property_verification_documents;"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// StringList disimpan sebagai JSON array di kolom text
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(l)
+	return string(b), err
+}
+
+func (l *StringList) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("StringList: tipe data tidak didukung %T", value)
+	}
+	return json.Unmarshal(data, l)
+}
+
+// ImageRendition satu ukuran foto hasil proses upload (thumb, card, full)
+type ImageRendition struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// ImageRenditions disimpan sebagai JSON array di kolom text
+type ImageRenditions []ImageRendition
+
+func (r ImageRenditions) Value() (driver.Value, error) {
+	if r == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(r)
+	return string(b), err
+}
+
+func (r *ImageRenditions) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*r = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("ImageRenditions: tipe data tidak didukung %T", value)
+	}
+	return json.Unmarshal(data, r)
+}
+
+// URL mengembalikan URL rendition dengan nama tertentu, kosong kalau tidak ada
+func (r ImageRenditions) URL(name string) string {
+	for _, rendition := range r {
+		if rendition.Name == name {
+			return rendition.URL
+		}
+	}
+	return ""
+}
@@ -6,14 +6,22 @@ import (
 	"gorm.io/gorm"
 )
 
+// Role user
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	Name      string         `gorm:"type:varchar(100);not null" json:"name"`
-	Email     string         `gorm:"type:varchar(100);uniqueIndex;not null" json:"email"`
-	Password  string         `gorm:"type:varchar(255);not null" json:"-"` // "-" agar password tidak muncul di JSON
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	Name           string         `gorm:"type:varchar(100);not null" json:"name"`
+	Email          string         `gorm:"type:varchar(100);uniqueIndex;not null" json:"email"`
+	Password       string         `gorm:"type:varchar(255);not null" json:"-"`                // "-" agar password tidak muncul di JSON
+	Role           string         `gorm:"type:varchar(20);not null;default:user" json:"role"` // user, admin
+	OrganizationID *uint          `gorm:"index" json:"organization_id,omitempty"`             // Agensi tempat user bergabung, optional
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Relasi: satu user punya banyak properties
 	Properties []Property `gorm:"foreignKey:UserID" json:"properties,omitempty"`
@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// Status viewing (jadwal kunjungan ke lokasi listing)
+const (
+	ViewingRequested = "requested" // Menunggu jawaban pihak yang tidak mengusulkan waktu
+	ViewingConfirmed = "confirmed"
+	ViewingDeclined  = "declined"
+	ViewingCancelled = "cancelled"
+)
+
+// ViewingSlot rentang waktu pemilik listing bersedia menerima kunjungan.
+// Pembeli memilih jam viewing di dalam salah satu slot.
+type ViewingSlot struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	PropertyID uint      `json:"property_id" gorm:"not null;index"`
+	OwnerID    uint      `json:"owner_id" gorm:"not null;index"`
+	StartsAt   time.Time `json:"starts_at" gorm:"not null;index"`
+	EndsAt     time.Time `json:"ends_at" gorm:"not null"`
+	Notes      string    `json:"notes" gorm:"type:varchar(500)"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Viewing permintaan kunjungan pembeli ke listing. Waktu yang diusulkan (saat booking atau
+// reschedule) dijawab oleh pihak lainnya: accept jadi confirmed, decline jadi declined.
+type Viewing struct {
+	ID              uint   `json:"id" gorm:"primaryKey"`
+	PropertyID      uint   `json:"property_id" gorm:"not null;index"`
+	PropertyTitle   string `json:"property_title,omitempty" gorm:"->;-:migration"`   // Diisi dari join ke properties saat dibaca
+	PropertyAddress string `json:"property_address,omitempty" gorm:"->;-:migration"` // Diisi dari join ke properties saat dibaca
+	OwnerID         uint   `json:"owner_id" gorm:"not null;index"`
+	BuyerID         uint   `json:"buyer_id" gorm:"not null;index"`
+
+	StartsAt    time.Time `json:"starts_at" gorm:"not null;index"`
+	EndsAt      time.Time `json:"ends_at" gorm:"not null;index"`
+	Status      string    `json:"status" gorm:"type:varchar(20);not null;default:requested;index"` // requested, confirmed, declined, cancelled
+	RequestedBy uint      `json:"requested_by"`                                                    // Yang terakhir mengusulkan waktu
+	Message     string    `json:"message" gorm:"type:text"`                                        // Catatan dari pembeli
+	Reason      string    `json:"reason,omitempty" gorm:"type:text"`                               // Alasan decline/cancel
+	Sequence    int       `json:"sequence" gorm:"not null;default:0"`                              // Revisi untuk iCalendar, naik setiap jadwal berubah
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// HasParticipant true kalau user adalah pembeli atau pemilik listing viewing ini
+func (v *Viewing) HasParticipant(userID uint) bool {
+	return userID != 0 && (v.BuyerID == userID || v.OwnerID == userID)
+}
+
+// CanRespond true kalau user boleh menerima/menolak waktu yang sedang diusulkan
+func (v *Viewing) CanRespond(userID uint) bool {
+	return v.Status == ViewingRequested && v.HasParticipant(userID) && v.RequestedBy != userID
+}
+
+// IsOpen true kalau viewing masih bisa di-reschedule atau dibatalkan
+func (v *Viewing) IsOpen() bool {
+	return v.Status == ViewingRequested || v.Status == ViewingConfirmed
+}
+
+// CalendarFeed token rahasia untuk feed iCalendar viewing milik user. Feed di-subscribe
+// dari aplikasi kalender tanpa login, jadi token bisa diganti kalau bocor.
+type CalendarFeed struct {
+	ID        uint      `json:"-" gorm:"primaryKey"`
+	UserID    uint      `json:"-" gorm:"not null;uniqueIndex"`
+	Token     string    `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	CreatedAt time.Time `json:"created_at"`
+}
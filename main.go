@@ -1,15 +1,21 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"project-zero/internal/models"
 	"project-zero/pkg/database"
 	"project-zero/pkg/handlers"
-	"project-zero/pkg/utils"
+	"project-zero/pkg/imaging"
+	"project-zero/pkg/media"
+	"project-zero/pkg/storage"
+	"project-zero/pkg/video"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -18,9 +24,25 @@ import (
 )
 
 var db *gorm.DB
+var store storage.Storage
+var privateStore storage.Storage
 var propertyHandler *handlers.PropertyHandler
 var propertyPhotoHandler *handlers.PropertyPhotoHandler
+var uploadHandler *handlers.UploadHandler
+var settingHandler *handlers.SettingHandler
+var mediaHandler *handlers.MediaHandler
+var mediaGC *media.Collector
 var authHandler *handlers.AuthHandler
+var amenityHandler *handlers.AmenityHandler
+var exchangeRateHandler *handlers.ExchangeRateHandler
+var propertyImportHandler *handlers.PropertyImportHandler
+var organizationHandler *handlers.OrganizationHandler
+var propertyDocumentHandler *handlers.PropertyDocumentHandler
+var propertyVerificationHandler *handlers.PropertyVerificationHandler
+var inquiryHandler *handlers.InquiryHandler
+var conversationHandler *handlers.ConversationHandler
+var viewingHandler *handlers.ViewingHandler
+var favoriteHandler *handlers.FavoriteHandler
 
 func initDB() {
 	// Ambil config dari .env
@@ -36,22 +58,55 @@ func initDB() {
 	fmt.Println("✅ Berhasil terhubung ke Neon Database!")
 
 	// Buat/update tabel otomatis
-	db.AutoMigrate(&models.User{}, &models.Property{}, &models.PropertyPhoto{})
+	db.AutoMigrate(&models.User{}, &models.Amenity{}, &models.Property{}, &models.PropertyPhoto{}, &models.PropertyPriceHistory{}, &models.ExchangeRate{}, &models.PropertyImport{}, &models.MediaAsset{}, &models.AppSetting{}, &models.Organization{}, &models.PropertyDocument{}, &models.PropertyVerification{}, &models.Inquiry{}, &models.Conversation{}, &models.Message{}, &models.MessageAttachment{}, &models.ViewingSlot{}, &models.Viewing{}, &models.CalendarFeed{}, &models.Favorite{}, &models.Shortlist{}, &models.ShortlistItem{})
+	if err := database.BackfillRentalTerms(db); err != nil {
+		fmt.Printf("⚠️  Gagal backfill syarat sewa listing WTR: %v\n", err)
+	}
+	if err := database.BackfillPriceHistory(db); err != nil {
+		fmt.Printf("⚠️  Gagal backfill riwayat harga listing: %v\n", err)
+	}
 
-	// Initialize Cloudinary
-	if err := utils.InitCloudinary(); err != nil {
-		fmt.Printf("⚠️  Warning: %v\n", err)
-		fmt.Println("📝 Silakan isi CLOUDINARY credentials di file .env")
+	// Initialize storage (Cloudinary, local disk atau S3 sesuai STORAGE_DRIVER)
+	store, err = storage.NewFromEnv()
+	if err != nil {
+		panic(fmt.Sprintf("❌ Gagal inisialisasi storage: %v", err))
 	}
+	fmt.Printf("✅ Storage driver: %s\n", store.Name())
+
+	// Storage private untuk file tanpa URL publik (foto asli tanpa watermark, dokumen legal)
+	privateStore, err = storage.NewPrivateFromEnv()
+	if err != nil {
+		panic(fmt.Sprintf("❌ Gagal inisialisasi storage private: %v", err))
+	}
+	fmt.Printf("✅ Private storage driver: %s\n", privateStore.Name())
 
 	// Initialize repository dan handler
 	propertyRepo := database.NewPropertyRepository(db)
-	propertyHandler = handlers.NewPropertyHandler(propertyRepo)
+	exchangeRateRepo := database.NewExchangeRateRepository(db)
+	favoriteRepo := database.NewFavoriteRepository(db)
+	propertyHandler = handlers.NewPropertyHandler(propertyRepo, exchangeRateRepo, favoriteRepo, store, privateStore)
 	propertyPhotoHandler = handlers.NewPropertyPhotoHandler(db)
+	settingRepo := database.NewSettingRepository(db)
+	settingHandler = handlers.NewSettingHandler(settingRepo)
+	mediaAssetRepo := database.NewMediaAssetRepository(db)
+	organizationRepo := database.NewOrganizationRepository(db)
+	organizationHandler = handlers.NewOrganizationHandler(organizationRepo, store)
+	propertyDocumentHandler = handlers.NewPropertyDocumentHandler(database.NewPropertyDocumentRepository(db), database.NewPropertyPhotoRepository(db), privateStore)
+	propertyVerificationHandler = handlers.NewPropertyVerificationHandler(database.NewPropertyVerificationRepository(db), database.NewPropertyPhotoRepository(db))
+	inquiryHandler = handlers.NewInquiryHandler(database.NewInquiryRepository(db))
+	conversationHandler = handlers.NewConversationHandler(database.NewConversationRepository(db))
+	viewingHandler = handlers.NewViewingHandler(database.NewViewingRepository(db), database.NewPropertyPhotoRepository(db))
+	favoriteHandler = handlers.NewFavoriteHandler(favoriteRepo, exchangeRateRepo)
+	uploadHandler = handlers.NewUploadHandler(store, privateStore, mediaAssetRepo, database.NewPropertyPhotoRepository(db), settingRepo, organizationRepo, imaging.NewProcessorFromEnv(), video.NewToolFromEnv())
+
+	// GC file upload yang tidak dipakai listing
+	mediaGC = media.NewCollector(mediaAssetRepo, store, privateStore, media.DurationFromEnv("MEDIA_GC_GRACE", media.DefaultGCGrace))
+	mediaHandler = handlers.NewMediaHandler(mediaGC, mediaAssetRepo)
 	authHandler = handlers.NewAuthHandler(db)
-
-	// Buat folder untuk upload foto jika belum ada (optional, backup only)
-	os.MkdirAll("./uploads", os.ModePerm)
+	amenityRepo := database.NewAmenityRepository(db)
+	amenityHandler = handlers.NewAmenityHandler(amenityRepo)
+	propertyImportHandler = handlers.NewPropertyImportHandler(propertyRepo, amenityRepo)
+	exchangeRateHandler = handlers.NewExchangeRateHandler(exchangeRateRepo)
 }
 
 // Middleware untuk HTTPS redirect di production
@@ -88,13 +143,45 @@ func securityHeadersMiddleware() gin.HandlerFunc {
 
 		// Content Security Policy (CSP)
 		if os.Getenv("ENVIRONMENT") == "production" {
-			c.Writer.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'self' 'unsafe-inline' https://cdn.tailwindcss.com; style-src 'self' 'unsafe-inline' https://fonts.googleapis.com; font-src 'self' https://fonts.gstatic.com; img-src 'self' data: https://res.cloudinary.com; connect-src 'self';")
+			c.Writer.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'self' 'unsafe-inline' https://cdn.tailwindcss.com; style-src 'self' 'unsafe-inline' https://fonts.googleapis.com; font-src 'self' https://fonts.gstatic.com; img-src 'self' data: https://res.cloudinary.com"+storageImgSrc()+"; connect-src 'self' https://api.cloudinary.com"+storageConnectSrc()+";")
 		}
 
 		c.Next()
 	}
 }
 
+// storageImgSrc menambahkan origin S3_PUBLIC_URL ke CSP img-src kalau memakai storage S3
+func storageImgSrc() string {
+	if u, err := url.Parse(os.Getenv("S3_PUBLIC_URL")); err == nil && u.Scheme != "" && u.Host != "" {
+		return " " + u.Scheme + "://" + u.Host
+	}
+	return ""
+}
+
+// storageConnectSrc menambahkan endpoint S3 ke CSP connect-src untuk upload langsung dari browser
+func storageConnectSrc() string {
+	endpoint := os.Getenv("S3_ENDPOINT")
+	if endpoint == "" {
+		return ""
+	}
+	scheme := "https"
+	if os.Getenv("S3_USE_SSL") == "false" {
+		scheme = "http"
+	}
+	return " " + scheme + "://" + endpoint
+}
+
+// trustedProxies daftar IP/CIDR reverse proxy dari TRUSTED_PROXIES (dipisah koma), nil kalau kosong
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
 // Middleware buat handle CORS (Cross-Origin Resource Sharing)
 func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -129,6 +216,9 @@ func corsMiddleware() gin.HandlerFunc {
 func main() {
 	initDB()
 
+	// GC media berkala (MEDIA_GC_INTERVAL=0 untuk mematikan)
+	mediaGC.Start(context.Background(), media.DurationFromEnv("MEDIA_GC_INTERVAL", media.DefaultGCInterval))
+
 	// Set Gin mode berdasarkan environment
 	if os.Getenv("ENVIRONMENT") == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -136,6 +226,13 @@ func main() {
 
 	r := gin.Default()
 
+	// IP client (rate limit inquiry, log) hanya diambil dari X-Real-IP yang diset nginx.
+	// Tanpa TRUSTED_PROXIES header diabaikan dan IP koneksi yang dipakai, supaya tidak bisa dipalsukan client.
+	r.RemoteIPHeaders = []string{"X-Real-IP"}
+	if err := r.SetTrustedProxies(trustedProxies()); err != nil {
+		panic(fmt.Sprintf("❌ TRUSTED_PROXIES tidak valid: %v", err))
+	}
+
 	// Set max multipart memory untuk support file besar
 	r.MaxMultipartMemory = 50 * 1024 * 1024 // 50MB
 
@@ -153,6 +250,19 @@ func main() {
 	r.StaticFile("/styles.css", "./styles.css")
 	r.StaticFile("/auth-helper.js", "./auth-helper.js")
 
+	// File dari local storage: publik di /uploads, signed URL di /storage
+	if local, ok := store.(*storage.LocalStorage); ok {
+		if strings.HasPrefix(local.BaseURL(), "/") {
+			r.Static(local.BaseURL(), local.Dir())
+		}
+		r.GET("/storage/*key", uploadHandler.ServeSignedFile)
+	}
+	// Storage private local: download lewat signed URL dan upload langsung (file mentah sebelum konfirmasi)
+	if _, ok := privateStore.(*storage.LocalStorage); ok {
+		r.GET("/private/*key", uploadHandler.ServePrivateFile)
+		r.PUT("/private/*key", uploadHandler.ReceiveDirectUpload)
+	}
+
 	// Health check endpoint (untuk monitoring & load balancer)
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -169,6 +279,15 @@ func main() {
 		auth.POST("/login", authHandler.Login)
 	}
 
+	// Pertanyaan calon pembeli (PUBLIC - pembeli tidak wajib punya akun)
+	r.POST("/properties/:id/inquiries", inquiryHandler.CreateInquiry)
+
+	// Feed iCalendar viewing (PUBLIC - di-subscribe aplikasi kalender, akses lewat token rahasia)
+	r.GET("/calendar/:token/viewings.ics", viewingHandler.GetCalendarFeed)
+
+	// Shortlist yang dibagikan (PUBLIC - akses lewat token di link share)
+	r.GET("/shared/shortlists/:token", favoriteHandler.GetSharedShortlist)
+
 	// Protected routes (PRIVATE - perlu login dengan JWT)
 	protected := r.Group("/")
 	protected.Use(handlers.AuthMiddleware())
@@ -176,20 +295,128 @@ func main() {
 		// Profile
 		protected.GET("/auth/profile", authHandler.GetProfile)
 
-		// Upload foto endpoint - upload langsung ke Cloudinary
-		protected.POST("/upload", handlers.UploadFile)
+		// Upload foto endpoint - upload ke storage yang dikonfigurasi
+		protected.POST("/upload", uploadHandler.UploadFile)
+		protected.POST("/upload/video", uploadHandler.UploadVideo)
+		protected.POST("/upload/sign", uploadHandler.SignUpload)
+		protected.POST("/upload/confirm", uploadHandler.ConfirmUpload)
+		protected.GET("/media/:id/original", uploadHandler.GetOriginalLink)
 
 		// Property routes
 		protected.POST("/properties", propertyHandler.CreateProperty)
 		protected.GET("/properties", propertyHandler.GetAllProperties)
+		protected.GET("/properties/export", propertyHandler.ExportProperties)
+		protected.POST("/properties/import", propertyImportHandler.ImportProperties)
+		protected.GET("/properties/imports/:id", propertyImportHandler.GetImport)
+		protected.GET("/properties/imports/:id/report", propertyImportHandler.DownloadImportReport)
 		protected.GET("/properties/:id", propertyHandler.GetPropertyByID)
+		protected.GET("/properties/:id/price-history", propertyHandler.GetPriceHistory)
+		protected.GET("/properties/:id/brochure.pdf", propertyHandler.GetBrochure)
 		protected.PUT("/properties/:id", propertyHandler.UpdateProperty)
 		protected.DELETE("/properties/:id", propertyHandler.DeleteProperty)
+		protected.PUT("/properties/:id/photos/order", propertyPhotoHandler.ReorderPropertyPhotos)
+
+		// Dokumen legal listing (storage private, download lewat link sementara)
+		protected.POST("/properties/:id/documents", propertyDocumentHandler.UploadPropertyDocument)
+		protected.GET("/properties/:id/documents", propertyDocumentHandler.GetPropertyDocuments)
+		protected.GET("/property-documents/expiring", propertyDocumentHandler.GetExpiringDocuments)
+		protected.GET("/property-documents/:id/download", propertyDocumentHandler.GetDocumentDownloadLink)
+		protected.PUT("/property-documents/:id", propertyDocumentHandler.UpdatePropertyDocument)
+		protected.DELETE("/property-documents/:id", propertyDocumentHandler.DeletePropertyDocument)
+		protected.POST("/properties/:id/verification", propertyVerificationHandler.SubmitVerification)
+		protected.GET("/properties/:id/verification", propertyVerificationHandler.GetPropertyVerifications)
+
+		// Inbox lead dari calon pembeli
+		protected.GET("/inquiries", inquiryHandler.GetInbox)
+		protected.GET("/inquiries/:id", inquiryHandler.GetInquiry)
+		protected.PUT("/inquiries/:id", inquiryHandler.UpdateInquiry)
+
+		// Percakapan pembeli dan pemilik listing
+		protected.POST("/properties/:id/conversations", conversationHandler.StartConversation)
+		protected.GET("/conversations", conversationHandler.GetConversations)
+		protected.GET("/conversations/unread-count", conversationHandler.GetUnreadCount)
+		protected.GET("/conversations/:id/messages", conversationHandler.GetMessages)
+		protected.POST("/conversations/:id/messages", conversationHandler.SendMessage)
+
+		// Jadwal viewing: slot ketersediaan, booking, undangan dan feed iCalendar
+		protected.POST("/properties/:id/viewing-slots", viewingHandler.CreateSlot)
+		protected.GET("/properties/:id/viewing-slots", viewingHandler.GetSlots)
+		protected.DELETE("/viewing-slots/:id", viewingHandler.DeleteSlot)
+		protected.POST("/properties/:id/viewings", viewingHandler.RequestViewing)
+		protected.GET("/viewings", viewingHandler.GetViewings)
+		protected.POST("/viewings/calendar-feed", viewingHandler.RotateCalendarFeed)
+		protected.DELETE("/viewings/calendar-feed", viewingHandler.DeleteCalendarFeed)
+		protected.GET("/viewings/:id", viewingHandler.GetViewing)
+		protected.GET("/viewings/:id/invite.ics", viewingHandler.GetViewingInvite)
+		protected.POST("/viewings/:id/accept", viewingHandler.AcceptViewing)
+		protected.POST("/viewings/:id/decline", viewingHandler.DeclineViewing)
+		protected.POST("/viewings/:id/reschedule", viewingHandler.RescheduleViewing)
+		protected.POST("/viewings/:id/cancel", viewingHandler.CancelViewing)
+
+		// Favorit dan shortlist pembeli
+		protected.POST("/properties/:id/favorite", favoriteHandler.AddFavorite)
+		protected.DELETE("/properties/:id/favorite", favoriteHandler.RemoveFavorite)
+		protected.GET("/favorites", favoriteHandler.GetFavorites)
+		protected.POST("/shortlists", favoriteHandler.CreateShortlist)
+		protected.GET("/shortlists", favoriteHandler.GetShortlists)
+		protected.GET("/shortlists/:id", favoriteHandler.GetShortlist)
+		protected.PUT("/shortlists/:id", favoriteHandler.UpdateShortlist)
+		protected.DELETE("/shortlists/:id", favoriteHandler.DeleteShortlist)
+		protected.POST("/shortlists/:id/items", favoriteHandler.AddShortlistItem)
+		protected.DELETE("/shortlists/:id/items/:property_id", favoriteHandler.RemoveShortlistItem)
+		protected.POST("/shortlists/:id/share", favoriteHandler.ShareShortlist)
+		protected.DELETE("/shortlists/:id/share", favoriteHandler.UnshareShortlist)
 
 		// Property photos routes
 		protected.POST("/property-photos", propertyPhotoHandler.AddPropertyPhoto)
 		protected.GET("/property-photos/:property_id", propertyPhotoHandler.GetPropertyPhotos)
+		protected.PUT("/property-photos/:id", propertyPhotoHandler.UpdatePhotoCaption)
+		protected.PUT("/property-photos/:id/cover", propertyPhotoHandler.SetCoverPhoto)
 		protected.DELETE("/property-photos/:id", propertyPhotoHandler.DeletePropertyPhoto)
+
+		// Amenity catalog (read)
+		protected.GET("/amenities", amenityHandler.GetAllAmenities)
+
+		// Exchange rates (read)
+		protected.GET("/exchange-rates", exchangeRateHandler.GetExchangeRates)
+	}
+
+	// Admin routes (perlu login + role admin)
+	admin := r.Group("/admin")
+	admin.Use(handlers.AuthMiddleware(), authHandler.AdminMiddleware())
+	{
+		admin.POST("/amenities", amenityHandler.CreateAmenity)
+		admin.PUT("/amenities/:id", amenityHandler.UpdateAmenity)
+		admin.DELETE("/amenities/:id", amenityHandler.DeleteAmenity)
+
+		admin.PUT("/exchange-rates", exchangeRateHandler.UpsertExchangeRates)
+		admin.POST("/exchange-rates/import", exchangeRateHandler.ImportExchangeRatesCSV)
+		admin.DELETE("/exchange-rates/:currency", exchangeRateHandler.DeleteExchangeRate)
+
+		// Pengaturan upload foto
+		admin.GET("/settings/metadata-allowlist", settingHandler.GetMetadataAllowlist)
+		admin.PUT("/settings/metadata-allowlist", settingHandler.UpdateMetadataAllowlist)
+
+		// Pembersihan file upload yang tidak dipakai
+		admin.GET("/media/orphans", mediaHandler.GetOrphanReport)
+		admin.POST("/media/gc", mediaHandler.RunGarbageCollection)
+		admin.GET("/media/duplicates", mediaHandler.GetSharedImages)
+
+		// Organisasi (agensi) dan watermark foto
+		admin.GET("/organizations", organizationHandler.GetAllOrganizations)
+		admin.POST("/organizations", organizationHandler.CreateOrganization)
+		admin.PUT("/organizations/:id/watermark", organizationHandler.UpdateWatermark)
+		admin.POST("/organizations/:id/watermark/logo", organizationHandler.UploadWatermarkLogo)
+		admin.PUT("/users/:id/organization", organizationHandler.SetUserOrganization)
+
+		// Dokumen legal listing
+		admin.GET("/properties/:id/documents", propertyDocumentHandler.AdminGetPropertyDocuments)
+		admin.GET("/property-documents/expiring", propertyDocumentHandler.AdminGetExpiringDocuments)
+		admin.GET("/property-documents/:id/download", propertyDocumentHandler.AdminGetDocumentDownloadLink)
+		admin.GET("/verifications", propertyVerificationHandler.GetVerifications)
+		admin.GET("/verifications/:id", propertyVerificationHandler.GetVerification)
+		admin.POST("/verifications/:id/approve", propertyVerificationHandler.ApproveVerification)
+		admin.POST("/verifications/:id/reject", propertyVerificationHandler.RejectVerification)
 	}
 
 	// Get port dari environment atau default
@@ -198,7 +425,7 @@ func main() {
 		port = "8080"
 	}
 
-	// Create HTTP server with custom timeouts
+	// Create HTTP server with custom timeouts (/upload/video memperpanjang deadline sendiri, lihat VideoUploadTimeout)
 	srv := &http.Server{
 		Addr:           ":" + port,
 		Handler:        r,
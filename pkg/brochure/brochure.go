@@ -0,0 +1,291 @@
+// Package brochure membuat brosur PDF listing properti sepenuhnya di server.
+package brochure
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"project-zero/internal/models"
+	"project-zero/pkg/rupiah"
+	"project-zero/pkg/storage"
+	"strconv"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/skip2/go-qrcode"
+)
+
+// MaxGalleryPhotos jumlah foto galeri maksimal di halaman kedua
+const MaxGalleryPhotos = 6
+
+// Data isi brosur
+type Data struct {
+	Property   models.Property
+	Photos     []models.PropertyPhoto
+	Agent      models.User
+	ListingURL string // Link listing publik untuk QR code
+	BrandName  string
+	Store      storage.Storage // Storage asal foto, foto di luar storage ini tidak diambil
+}
+
+// brandColor warna utama brosur (biru)
+var brandColor = [3]int{30, 64, 175}
+
+var pricePeriodLabels = map[string]string{
+	models.PricePeriodDaily:   " / hari",
+	models.PricePeriodMonthly: " / bulan",
+	models.PricePeriodYearly:  " / tahun",
+}
+
+// Render menulis brosur PDF ke w. Halaman pertama berisi cover, harga, spesifikasi,
+// alamat, kontak agen dan QR code; halaman kedua berisi galeri foto (kalau ada).
+func Render(ctx context.Context, w io.Writer, data Data) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(false, 15)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	p := data.Property
+	photos := galleryPhotos(data)
+
+	// Halaman 1
+	pdf.AddPage()
+	drawHeader(pdf, tr, data.BrandName)
+
+	pdf.SetXY(15, 26)
+	pdf.SetTextColor(15, 23, 42)
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.MultiCell(180, 8, tr(p.Title), "", "L", false)
+
+	pdf.SetX(15)
+	pdf.SetTextColor(brandColor[0], brandColor[1], brandColor[2])
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(180, 9, tr(priceText(p)), "", 1, "L", false, 0, "")
+
+	// Cover
+	coverY := pdf.GetY() + 2
+	coverH := 95.0
+	if len(photos) > 0 {
+		drawImage(ctx, data.Store, pdf, "cover", photos[0], 15, coverY, 180, coverH)
+	} else {
+		pdf.SetFillColor(226, 232, 240)
+		pdf.Rect(15, coverY, 180, coverH, "F")
+	}
+	pdf.SetY(coverY + coverH + 6)
+
+	drawSpecs(pdf, tr, p)
+	drawAddress(pdf, tr, p)
+	drawFooter(pdf, tr, data)
+
+	// Halaman 2: galeri
+	if len(photos) > 1 {
+		pdf.AddPage()
+		drawHeader(pdf, tr, data.BrandName)
+		pdf.SetXY(15, 26)
+		pdf.SetTextColor(15, 23, 42)
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.CellFormat(180, 8, "Galeri Foto", "", 1, "L", false, 0, "")
+
+		for i, src := range photos[1:] {
+			col, row := i%2, i/2
+			x := 15 + float64(col)*92
+			y := 38 + float64(row)*78
+			drawImage(ctx, data.Store, pdf, "gallery-"+strconv.Itoa(i), src, x, y, 88, 72)
+		}
+	}
+
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	return pdf.Output(w)
+}
+
+// galleryPhotos urutan foto: cover (PhotoPath) lalu foto tambahan, tanpa duplikat
+func galleryPhotos(data Data) []string {
+	var photos []string
+	seen := map[string]bool{}
+	add := func(src string) {
+		if src != "" && !seen[src] && len(photos) < MaxGalleryPhotos+1 {
+			seen[src] = true
+			photos = append(photos, src)
+		}
+	}
+
+	add(data.Property.PhotoPath)
+	for _, photo := range data.Photos {
+		add(photo.PhotoPath)
+	}
+	return photos
+}
+
+func priceText(p models.Property) string {
+	if p.PriceHidden {
+		return "Hubungi kami"
+	}
+
+	text := rupiah.Format(p.Price)
+	if p.Currency != "" && p.Currency != "IDR" {
+		text = p.Currency + " " + rupiah.FormatNumber(p.Price)
+	}
+	if p.ListingType == "WTR" {
+		text += pricePeriodLabels[p.PricePeriod]
+	}
+	if p.PriceNegotiable {
+		text += " (Nego)"
+	}
+	return text
+}
+
+func drawHeader(pdf *fpdf.Fpdf, tr func(string) string, brand string) {
+	pdf.SetFillColor(brandColor[0], brandColor[1], brandColor[2])
+	pdf.Rect(0, 0, 210, 18, "F")
+	pdf.SetXY(15, 5)
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(120, 8, tr(brand), "", 0, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.CellFormat(60, 8, "Brosur Properti", "", 0, "R", false, 0, "")
+}
+
+func drawSpecs(pdf *fpdf.Fpdf, tr func(string) string, p models.Property) {
+	listing := "Dijual"
+	if p.ListingType == "WTR" {
+		listing = "Disewa"
+	}
+
+	specs := [][2]string{
+		{"Tipe Listing", listing},
+		{"Sertifikat", p.Certificate},
+		{"Luas Tanah", fmt.Sprintf("%d m²", p.LandSize)},
+		{"Luas Bangunan", fmt.Sprintf("%d m²", p.BuildingSize)},
+		{"Kamar Tidur", strconv.Itoa(p.Bedrooms)},
+		{"Kamar Mandi", strconv.Itoa(p.Bathrooms)},
+		{"Jumlah Lantai", strconv.Itoa(p.Floors)},
+		{"Listrik", optionalText(p.Electricity > 0, fmt.Sprintf("%d Watt", p.Electricity))},
+		{"Sumber Air", optionalText(p.WaterSource != "", p.WaterSource)},
+	}
+	if p.ListingType == "WTR" && p.MinLeaseMonths > 0 {
+		specs = append(specs, [2]string{"Minimal Sewa", fmt.Sprintf("%d bulan", p.MinLeaseMonths)})
+	}
+	if len(p.Amenities) > 0 {
+		names := make([]string, len(p.Amenities))
+		for i, a := range p.Amenities {
+			names[i] = a.Name
+		}
+		specs = append(specs, [2]string{"Fasilitas", strings.Join(names, ", ")})
+	}
+
+	pdf.SetTextColor(15, 23, 42)
+	for i, spec := range specs {
+		// Dua kolom, fasilitas (yang panjang) selalu satu baris penuh
+		if spec[0] == "Fasilitas" {
+			if i%2 == 1 {
+				pdf.Ln(7)
+			}
+			pdf.SetX(15)
+			pdf.SetFont("Helvetica", "", 9)
+			pdf.CellFormat(32, 7, tr(spec[0]), "B", 0, "L", false, 0, "")
+			pdf.SetFont("Helvetica", "B", 9)
+			pdf.MultiCell(148, 7, tr(spec[1]), "B", "L", false)
+			continue
+		}
+
+		x := 15.0
+		if i%2 == 1 {
+			x = 107
+		}
+		pdf.SetX(x)
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.CellFormat(32, 7, tr(spec[0]), "B", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "B", 9)
+		ln := 0
+		if i%2 == 1 || i == len(specs)-1 {
+			ln = 1
+		}
+		pdf.CellFormat(56, 7, tr(spec[1]), "B", ln, "L", false, 0, "")
+	}
+}
+
+func drawAddress(pdf *fpdf.Fpdf, tr func(string) string, p models.Property) {
+	pdf.Ln(4)
+	pdf.SetX(15)
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(180, 6, "Alamat", "", 1, "L", false, 0, "")
+	pdf.SetX(15)
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.MultiCell(180, 5, tr(p.Address), "", "L", false)
+
+	if p.Description != "" {
+		pdf.Ln(2)
+		pdf.SetX(15)
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.MultiCell(180, 4.5, tr(truncate(p.Description, 450)), "", "L", false)
+	}
+}
+
+// drawFooter kotak kontak agen dan QR code ke listing publik di bagian bawah halaman
+func drawFooter(pdf *fpdf.Fpdf, tr func(string) string, data Data) {
+	top := 245.0
+	pdf.SetDrawColor(203, 213, 225)
+	pdf.Line(15, top-4, 195, top-4)
+
+	pdf.SetXY(15, top)
+	pdf.SetTextColor(100, 116, 139)
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.CellFormat(140, 5, "Hubungi Agen", "", 1, "L", false, 0, "")
+	pdf.SetX(15)
+	pdf.SetTextColor(15, 23, 42)
+	pdf.SetFont("Helvetica", "B", 13)
+	pdf.CellFormat(140, 7, tr(data.Agent.Name), "", 1, "L", false, 0, "")
+	pdf.SetX(15)
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(140, 6, tr(data.Agent.Email), "", 1, "L", false, 0, "")
+	if data.ListingURL != "" {
+		pdf.SetX(15)
+		pdf.SetTextColor(brandColor[0], brandColor[1], brandColor[2])
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.CellFormat(140, 6, tr(data.ListingURL), "", 1, "L", false, 0, data.ListingURL)
+	}
+
+	if data.ListingURL == "" {
+		return
+	}
+	png, err := qrcode.Encode(data.ListingURL, qrcode.Medium, 256)
+	if err != nil {
+		return
+	}
+	pdf.RegisterImageOptionsReader("qr", fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(png))
+	pdf.ImageOptions("qr", 163, top-2, 32, 32, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, data.ListingURL)
+}
+
+// drawImage menggambar foto di kotak; foto yang gagal diambil diganti kotak abu-abu
+func drawImage(ctx context.Context, store storage.Storage, pdf *fpdf.Fpdf, name, src string, x, y, w, h float64) {
+	img, err := loadImage(ctx, store, src)
+	if err != nil {
+		fmt.Printf("⚠️  Brosur: %v\n", err)
+		pdf.SetFillColor(226, 232, 240)
+		pdf.Rect(x, y, w, h, "F")
+		return
+	}
+
+	opts := fpdf.ImageOptions{ImageType: "JPG"}
+	pdf.RegisterImageOptionsReader(name, opts, bytes.NewReader(img.data))
+	ix, iy, iw, ih := fitBox(img, x, y, w, h)
+	pdf.ImageOptions(name, ix, iy, iw, ih, false, opts, 0, "")
+}
+
+func optionalText(ok bool, text string) string {
+	if !ok {
+		return "-"
+	}
+	return text
+}
+
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max]) + "..."
+}
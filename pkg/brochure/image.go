@@ -0,0 +1,105 @@
+package brochure
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"io"
+	"project-zero/pkg/imaging"
+	"project-zero/pkg/storage"
+
+	_ "image/gif"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+)
+
+// MaxImageBytes batas ukuran satu foto yang diambil untuk brosur
+const MaxImageBytes = 15 * 1024 * 1024
+
+// loadedImage foto yang sudah di-encode ulang ke JPEG supaya bisa dipakai fpdf
+type loadedImage struct {
+	data   []byte
+	width  int
+	height int
+}
+
+// loadImage mengambil foto dari storage lalu meng-encode ulang ke JPEG (fpdf tidak mendukung WebP).
+// Hanya URL yang dikenali storage yang dibaca; URL luar atau path lokal tidak pernah diambil.
+func loadImage(ctx context.Context, store storage.Storage, src string) (*loadedImage, error) {
+	raw, err := readImageSource(ctx, store, src)
+	if err != nil {
+		return nil, err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("Gagal decode foto %s: %v", src, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 ||
+		config.Width > imaging.MaxImageDimension || config.Height > imaging.MaxImageDimension ||
+		int64(config.Width)*int64(config.Height) > imaging.MaxImagePixels {
+		return nil, fmt.Errorf("Dimensi foto %s terlalu besar (%dx%d)", src, config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("Gagal decode foto %s: %v", src, err)
+	}
+
+	// Gambar transparan diberi latar putih
+	bounds := img.Bounds()
+	canvas := image.NewRGBA(bounds)
+	draw.Draw(canvas, bounds, &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(canvas, bounds, img, bounds.Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, canvas, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	return &loadedImage{data: buf.Bytes(), width: bounds.Dx(), height: bounds.Dy()}, nil
+}
+
+func readImageSource(ctx context.Context, store storage.Storage, src string) ([]byte, error) {
+	if store == nil {
+		return nil, fmt.Errorf("Storage foto tidak dikonfigurasi")
+	}
+	key, ok := store.KeyFromURL(src)
+	if !ok {
+		return nil, fmt.Errorf("Foto %s bukan file storage, dilewati", src)
+	}
+	f, err := store.Open(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("Gagal membuka foto %s: %v", src, err)
+	}
+	defer f.Close()
+	return readLimited(f)
+}
+
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxImageBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxImageBytes {
+		return nil, fmt.Errorf("Foto terlalu besar untuk brosur")
+	}
+	return data, nil
+}
+
+// fitBox menghitung ukuran dan posisi gambar agar muat di kotak (x, y, w, h) tanpa merubah rasio
+func fitBox(img *loadedImage, x, y, w, h float64) (float64, float64, float64, float64) {
+	if img.width == 0 || img.height == 0 {
+		return x, y, w, h
+	}
+	ratio := float64(img.width) / float64(img.height)
+	fw, fh := w, w/ratio
+	if fh > h {
+		fw, fh = h*ratio, h
+	}
+	return x + (w-fw)/2, y + (h-fh)/2, fw, fh
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"errors"
+	"project-zero/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrUnknownAmenity dikembalikan kalau amenity_ids berisi ID yang tidak ada di katalog
+var ErrUnknownAmenity = errors.New("amenity tidak terdaftar")
+
+// ErrAmenitySlugTaken dikembalikan kalau slug sudah dipakai amenity lain
+var ErrAmenitySlugTaken = errors.New("Slug sudah dipakai amenity lain")
+
+type AmenityRepository struct {
+	db *gorm.DB
+}
+
+func NewAmenityRepository(db *gorm.DB) *AmenityRepository {
+	return &AmenityRepository{db: db}
+}
+
+func (r *AmenityRepository) GetAllAmenities() ([]models.Amenity, error) {
+	var amenities []models.Amenity
+	err := r.db.Order("category ASC, name ASC").Find(&amenities).Error
+	return amenities, err
+}
+
+func (r *AmenityRepository) GetAmenityByID(id uint) (*models.Amenity, error) {
+	var amenity models.Amenity
+	if err := r.db.First(&amenity, id).Error; err != nil {
+		return nil, err
+	}
+	return &amenity, nil
+}
+
+func (r *AmenityRepository) CreateAmenity(amenity *models.Amenity) error {
+	if err := r.checkSlug(amenity.Slug, 0); err != nil {
+		return err
+	}
+	return r.db.Create(amenity).Error
+}
+
+func (r *AmenityRepository) UpdateAmenity(id uint, amenity *models.Amenity) (*models.Amenity, error) {
+	existing, err := r.GetAmenityByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.checkSlug(amenity.Slug, id); err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"name":     amenity.Name,
+		"slug":     amenity.Slug,
+		"category": amenity.Category,
+	}
+	if err := r.db.Model(existing).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	return r.GetAmenityByID(id)
+}
+
+// checkSlug memastikan slug belum dipakai amenity lain selain excludeID
+func (r *AmenityRepository) checkSlug(slug string, excludeID uint) error {
+	var count int64
+	if err := r.db.Model(&models.Amenity{}).Where("slug = ? AND id <> ?", slug, excludeID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrAmenitySlugTaken
+	}
+	return nil
+}
+
+// DeleteAmenity menghapus amenity dari katalog sekaligus melepasnya dari semua property
+func (r *AmenityRepository) DeleteAmenity(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM property_amenities WHERE amenity_id = ?", id).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.Amenity{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"encoding/json"
+	"project-zero/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SettingRepository struct {
+	db *gorm.DB
+}
+
+func NewSettingRepository(db *gorm.DB) *SettingRepository {
+	return &SettingRepository{db: db}
+}
+
+// GetSetting mengambil nilai setting, string kosong kalau belum pernah diset
+func (r *SettingRepository) GetSetting(key string) (string, error) {
+	var setting models.AppSetting
+	err := r.db.Where("key = ?", key).First(&setting).Error
+	if err == gorm.ErrRecordNotFound {
+		return "", nil
+	}
+	return setting.Value, err
+}
+
+// SetSetting menyimpan atau menimpa nilai setting
+func (r *SettingRepository) SetSetting(key, value string) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(&models.AppSetting{Key: key, Value: value}).Error
+}
+
+// GetMetadataAllowlist mengambil daftar tag EXIF yang dipertahankan saat upload foto
+func (r *SettingRepository) GetMetadataAllowlist() ([]string, error) {
+	value, err := r.GetSetting(models.SettingMetadataAllowlist)
+	if err != nil || value == "" {
+		return []string{}, err
+	}
+	var tags []string
+	if err := json.Unmarshal([]byte(value), &tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// SetMetadataAllowlist menyimpan daftar tag EXIF yang dipertahankan saat upload foto
+func (r *SettingRepository) SetMetadataAllowlist(tags []string) error {
+	value, err := json.Marshal(tags)
+	if err != nil {
+		return err
+	}
+	return r.SetSetting(models.SettingMetadataAllowlist, string(value))
+}
@@ -0,0 +1,173 @@
+package database
+
+import (
+	"errors"
+	"project-zero/internal/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MessagePreviewLength panjang maksimal cuplikan pesan terakhir di daftar thread (karakter)
+const MessagePreviewLength = 200
+
+// Error percakapan
+var (
+	ErrOwnListingConversation = errors.New("Tidak bisa memulai percakapan dengan listing milik sendiri")
+	ErrAttachmentNotFound     = errors.New("Lampiran tidak ditemukan, upload dulu lewat /upload")
+)
+
+type ConversationRepository struct {
+	db *gorm.DB
+}
+
+func NewConversationRepository(db *gorm.DB) *ConversationRepository {
+	return &ConversationRepository{db: db}
+}
+
+// FindOrCreateConversation mengambil thread pembeli untuk listing, dibuat kalau belum ada.
+// gorm.ErrRecordNotFound kalau listing tidak ada.
+func (r *ConversationRepository) FindOrCreateConversation(propertyID, buyerID uint) (*models.Conversation, error) {
+	var conversation models.Conversation
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var property models.Property
+		if err := tx.Select("id", "user_id").First(&property, propertyID).Error; err != nil {
+			return err
+		}
+		if property.UserID == buyerID {
+			return ErrOwnListingConversation
+		}
+		return tx.Where(models.Conversation{PropertyID: propertyID, BuyerID: buyerID}).
+			Attrs(models.Conversation{OwnerID: property.UserID}).
+			FirstOrCreate(&conversation).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetConversation(conversation.ID, buyerID)
+}
+
+// GetConversation mengambil satu thread beserta judul listing dan jumlah pesan belum dibaca viewerID
+func (r *ConversationRepository) GetConversation(id, viewerID uint) (*models.Conversation, error) {
+	var conversation models.Conversation
+	if err := withConversationInfo(r.db.Model(&models.Conversation{}), viewerID).
+		Where("conversations.id = ?", id).First(&conversation).Error; err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+// GetUserConversations mengambil thread di mana user adalah pembeli atau pemilik listing,
+// yang paling baru aktif dulu. propertyID 0 berarti semua listing.
+func (r *ConversationRepository) GetUserConversations(userID, propertyID uint, page, limit int) ([]models.Conversation, int64, error) {
+	query := r.db.Model(&models.Conversation{}).
+		Where("(conversations.buyer_id = ? OR conversations.owner_id = ?)", userID, userID)
+	if propertyID > 0 {
+		query = query.Where("conversations.property_id = ?", propertyID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	conversations := []models.Conversation{}
+	err := withConversationInfo(query, userID).
+		Order("COALESCE(conversations.last_message_at, conversations.created_at) DESC, conversations.id DESC").
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&conversations).Error
+	return conversations, total, err
+}
+
+// CountUnread menghitung semua pesan belum dibaca user di semua thread-nya
+func (r *ConversationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Message{}).
+		Joins("JOIN conversations ON conversations.id = messages.conversation_id").
+		Where("(conversations.buyer_id = ? OR conversations.owner_id = ?) AND messages.sender_id <> ? AND messages.read_at IS NULL", userID, userID, userID).
+		Count(&count).Error
+	return count, err
+}
+
+// AddMessage menyimpan pesan beserta lampirannya dan memperbarui info pesan terakhir thread.
+// Lampiran harus MediaAsset milik pengirim, ErrAttachmentNotFound kalau tidak.
+func (r *ConversationRepository) AddMessage(conversation *models.Conversation, message *models.Message, attachmentURLs []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		assets, err := findAssetsByURL(tx, attachmentURLs)
+		if err != nil {
+			return err
+		}
+		message.Attachments = make([]models.MessageAttachment, 0, len(attachmentURLs))
+		for _, url := range attachmentURLs {
+			asset := assets[url]
+			if asset == nil || asset.UserID != message.SenderID || asset.Status != models.MediaStatusReady {
+				return ErrAttachmentNotFound
+			}
+			var attachment models.MessageAttachment
+			attachment.ApplyAsset(asset)
+			message.Attachments = append(message.Attachments, attachment)
+		}
+
+		message.ConversationID = conversation.ID
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+
+		preview := messagePreview(message)
+		if err := tx.Model(conversation).Updates(map[string]interface{}{
+			"last_message_at":      message.CreatedAt,
+			"last_message_preview": preview,
+		}).Error; err != nil {
+			return err
+		}
+		conversation.LastMessageAt = &message.CreatedAt
+		conversation.LastMessagePreview = preview
+		return refreshAttachment(tx, attachmentURLs...)
+	})
+}
+
+// GetMessages mengambil pesan thread dengan pagination, yang terbaru dulu
+func (r *ConversationRepository) GetMessages(conversationID uint, page, limit int) ([]models.Message, int64, error) {
+	query := r.db.Model(&models.Message{}).Where("conversation_id = ?", conversationID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	messages := []models.Message{}
+	err := query.Preload("Attachments").
+		Order("created_at DESC, id DESC").
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&messages).Error
+	return messages, total, err
+}
+
+// MarkRead menandai semua pesan dari lawan bicara di thread sudah dibaca readerID
+func (r *ConversationRepository) MarkRead(conversationID, readerID uint) error {
+	return r.db.Model(&models.Message{}).
+		Where("conversation_id = ? AND sender_id <> ? AND read_at IS NULL", conversationID, readerID).
+		Update("read_at", time.Now()).Error
+}
+
+// withConversationInfo menambahkan judul listing (LEFT JOIN karena listing bisa sudah dihapus)
+// dan jumlah pesan belum dibaca viewerID
+func withConversationInfo(query *gorm.DB, viewerID uint) *gorm.DB {
+	return query.Select(`conversations.*, properties.title AS property_title,
+		(SELECT COUNT(*) FROM messages WHERE messages.conversation_id = conversations.id
+			AND messages.sender_id <> ? AND messages.read_at IS NULL) AS unread_count`, viewerID).
+		Joins("LEFT JOIN properties ON properties.id = conversations.property_id")
+}
+
+// messagePreview cuplikan pesan untuk daftar thread, pesan tanpa teks ditampilkan sebagai lampiran
+func messagePreview(message *models.Message) string {
+	body := []rune(strings.Join(strings.Fields(message.Body), " "))
+	if len(body) == 0 {
+		return "[Lampiran]"
+	}
+	if len(body) > MessagePreviewLength {
+		return string(body[:MessagePreviewLength-1]) + "…"
+	}
+	return string(body)
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"project-zero/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExchangeRateRepository struct {
+	db *gorm.DB
+}
+
+func NewExchangeRateRepository(db *gorm.DB) *ExchangeRateRepository {
+	return &ExchangeRateRepository{db: db}
+}
+
+func (r *ExchangeRateRepository) GetAllRates() ([]models.ExchangeRate, error) {
+	rates := []models.ExchangeRate{}
+	err := r.db.Order("currency ASC").Find(&rates).Error
+	return rates, err
+}
+
+// GetRatesMap mengembalikan kurs dalam bentuk map currency -> IDR per unit
+func (r *ExchangeRateRepository) GetRatesMap() (map[string]float64, error) {
+	rates, err := r.GetAllRates()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]float64, len(rates))
+	for _, rate := range rates {
+		result[rate.Currency] = rate.IDRPerUnit
+	}
+	return result, nil
+}
+
+// UpsertRates menyimpan kurs baru atau menimpa kurs lama berdasarkan currency, dalam satu transaksi
+func (r *ExchangeRateRepository) UpsertRates(rates []models.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "currency"}},
+			DoUpdates: clause.AssignmentColumns([]string{"idr_per_unit", "updated_at"}),
+		}).Create(&rates).Error
+	})
+}
+
+func (r *ExchangeRateRepository) DeleteRate(currency string) error {
+	result := r.db.Where("currency = ?", currency).Delete(&models.ExchangeRate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
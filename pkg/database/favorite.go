@@ -0,0 +1,254 @@
+package database
+
+import (
+	"errors"
+	"project-zero/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Error favorit dan shortlist
+var (
+	ErrOwnListingFavorite = errors.New("Tidak bisa menyimpan listing milik sendiri ke favorit")
+	ErrShortlistLimit     = errors.New("Jumlah shortlist sudah maksimal")
+	ErrShortlistFull      = errors.New("Shortlist sudah penuh")
+)
+
+type FavoriteRepository struct {
+	db *gorm.DB
+}
+
+func NewFavoriteRepository(db *gorm.DB) *FavoriteRepository {
+	return &FavoriteRepository{db: db}
+}
+
+// AddFavorite menyimpan listing ke favorit user, tidak error kalau sudah ada.
+// gorm.ErrRecordNotFound kalau listing tidak ada.
+func (r *FavoriteRepository) AddFavorite(userID, propertyID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var property models.Property
+		if err := tx.Select("id", "user_id").First(&property, propertyID).Error; err != nil {
+			return err
+		}
+		if property.UserID == userID {
+			return ErrOwnListingFavorite
+		}
+		favorite := models.Favorite{UserID: userID, PropertyID: propertyID}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&favorite).Error
+	})
+}
+
+// RemoveFavorite menghapus listing dari favorit user, gorm.ErrRecordNotFound kalau memang tidak ada
+func (r *FavoriteRepository) RemoveFavorite(userID, propertyID uint) error {
+	result := r.db.Where("user_id = ? AND property_id = ?", userID, propertyID).Delete(&models.Favorite{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetFavorites mengambil favorit user beserta listing-nya, yang terakhir disimpan dulu
+func (r *FavoriteRepository) GetFavorites(userID uint, page, limit int) ([]models.Favorite, int64, error) {
+	query := r.db.Model(&models.Favorite{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	favorites := []models.Favorite{}
+	err := query.Preload("Property").
+		Order("created_at DESC, id DESC").
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&favorites).Error
+	return favorites, total, err
+}
+
+// FavoritePropertyIDs mengembalikan listing mana saja (dari propertyIDs) yang disimpan user
+func (r *FavoriteRepository) FavoritePropertyIDs(userID uint, propertyIDs []uint) (map[uint]bool, error) {
+	result := make(map[uint]bool)
+	if userID == 0 || len(propertyIDs) == 0 {
+		return result, nil
+	}
+	var ids []uint
+	if err := r.db.Model(&models.Favorite{}).
+		Where("user_id = ? AND property_id IN ?", userID, propertyIDs).
+		Pluck("property_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		result[id] = true
+	}
+	return result, nil
+}
+
+// CountFavorites menghitung jumlah user yang menyimpan tiap listing, key map adalah property ID
+func (r *FavoriteRepository) CountFavorites(propertyIDs []uint) (map[uint]int64, error) {
+	result := make(map[uint]int64)
+	if len(propertyIDs) == 0 {
+		return result, nil
+	}
+	var rows []struct {
+		PropertyID uint
+		Count      int64
+	}
+	if err := r.db.Model(&models.Favorite{}).
+		Select("property_id, COUNT(*) AS count").
+		Where("property_id IN ?", propertyIDs).
+		Group("property_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.PropertyID] = row.Count
+	}
+	return result, nil
+}
+
+// CreateShortlist membuat shortlist baru, ErrShortlistLimit kalau user sudah punya terlalu banyak
+func (r *FavoriteRepository) CreateShortlist(shortlist *models.Shortlist) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Shortlist{}).Where("user_id = ?", shortlist.UserID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= models.MaxShortlists {
+			return ErrShortlistLimit
+		}
+		return tx.Create(shortlist).Error
+	})
+}
+
+// GetUserShortlists mengambil shortlist user beserta jumlah listing di dalamnya, yang terakhir diubah dulu
+func (r *FavoriteRepository) GetUserShortlists(userID uint) ([]models.Shortlist, error) {
+	shortlists := []models.Shortlist{}
+	err := withItemCount(r.db.Model(&models.Shortlist{})).
+		Where("shortlists.user_id = ?", userID).
+		Order("shortlists.updated_at DESC, shortlists.id DESC").
+		Find(&shortlists).Error
+	return shortlists, err
+}
+
+// GetShortlistByID mengambil satu shortlist tanpa item
+func (r *FavoriteRepository) GetShortlistByID(id uint) (*models.Shortlist, error) {
+	var shortlist models.Shortlist
+	if err := withItemCount(r.db.Model(&models.Shortlist{})).Where("shortlists.id = ?", id).First(&shortlist).Error; err != nil {
+		return nil, err
+	}
+	return &shortlist, nil
+}
+
+// GetShortlistByToken mengambil shortlist yang dibagikan lewat link
+func (r *FavoriteRepository) GetShortlistByToken(token string) (*models.Shortlist, error) {
+	var shortlist models.Shortlist
+	if err := withItemCount(r.db.Model(&models.Shortlist{})).Where("shortlists.share_token = ?", token).First(&shortlist).Error; err != nil {
+		return nil, err
+	}
+	return &shortlist, nil
+}
+
+// LoadItems mengisi item shortlist beserta listing-nya, urut waktu ditambahkan
+func (r *FavoriteRepository) LoadItems(shortlist *models.Shortlist) error {
+	items := []models.ShortlistItem{}
+	if err := r.db.Where("shortlist_id = ?", shortlist.ID).Preload("Property").Order("created_at ASC, id ASC").Find(&items).Error; err != nil {
+		return err
+	}
+	shortlist.Items = items
+	return nil
+}
+
+// UpdateShortlist menyimpan nama dan deskripsi shortlist
+func (r *FavoriteRepository) UpdateShortlist(shortlist *models.Shortlist) error {
+	return r.db.Model(shortlist).Select("name", "description").Updates(shortlist).Error
+}
+
+// DeleteShortlist menghapus shortlist beserta item-nya
+func (r *FavoriteRepository) DeleteShortlist(shortlist *models.Shortlist) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("shortlist_id = ?", shortlist.ID).Delete(&models.ShortlistItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(shortlist).Error
+	})
+}
+
+// AddShortlistItem menambahkan listing ke shortlist, catatan diperbarui kalau listing sudah ada.
+// gorm.ErrRecordNotFound kalau listing tidak ada.
+func (r *FavoriteRepository) AddShortlistItem(shortlist *models.Shortlist, item *models.ShortlistItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id").First(&models.Property{}, item.PropertyID).Error; err != nil {
+			return err
+		}
+
+		var existing int64
+		if err := tx.Model(&models.ShortlistItem{}).
+			Where("shortlist_id = ? AND property_id = ?", shortlist.ID, item.PropertyID).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing == 0 && shortlist.ItemCount >= models.MaxShortlistItems {
+			return ErrShortlistFull
+		}
+
+		item.ShortlistID = shortlist.ID
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "shortlist_id"}, {Name: "property_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"note"}),
+		}).Create(item).Error; err != nil {
+			return err
+		}
+		return tx.Model(shortlist).Update("updated_at", gorm.Expr("NOW()")).Error
+	})
+}
+
+// RemoveShortlistItem menghapus listing dari shortlist, gorm.ErrRecordNotFound kalau tidak ada
+func (r *FavoriteRepository) RemoveShortlistItem(shortlist *models.Shortlist, propertyID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("shortlist_id = ? AND property_id = ?", shortlist.ID, propertyID).Delete(&models.ShortlistItem{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Model(shortlist).Update("updated_at", gorm.Expr("NOW()")).Error
+	})
+}
+
+// ShareShortlist membuat link share baru untuk shortlist, link lama tidak berlaku lagi
+func (r *FavoriteRepository) ShareShortlist(shortlist *models.Shortlist) error {
+	token, err := newSecretToken()
+	if err != nil {
+		return err
+	}
+	if err := r.db.Model(shortlist).Update("share_token", token).Error; err != nil {
+		return err
+	}
+	shortlist.ShareToken = &token
+	return nil
+}
+
+// UnshareShortlist mematikan link share shortlist
+func (r *FavoriteRepository) UnshareShortlist(shortlist *models.Shortlist) error {
+	if err := r.db.Model(shortlist).Update("share_token", nil).Error; err != nil {
+		return err
+	}
+	shortlist.ShareToken = nil
+	return nil
+}
+
+// deleteFavorites menghapus favorit dan item shortlist yang menunjuk ke listing yang dihapus
+func deleteFavorites(tx *gorm.DB, propertyID uint) error {
+	if err := tx.Where("property_id = ?", propertyID).Delete(&models.Favorite{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("property_id = ?", propertyID).Delete(&models.ShortlistItem{}).Error
+}
+
+func withItemCount(query *gorm.DB) *gorm.DB {
+	return query.Select("shortlists.*, (SELECT COUNT(*) FROM shortlist_items WHERE shortlist_items.shortlist_id = shortlists.id) AS item_count")
+}
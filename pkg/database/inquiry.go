@@ -0,0 +1,128 @@
+package database
+
+import (
+	"project-zero/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type InquiryRepository struct {
+	db *gorm.DB
+}
+
+func NewInquiryRepository(db *gorm.DB) *InquiryRepository {
+	return &InquiryRepository{db: db}
+}
+
+// InquiryFilter filter inbox pemilik listing
+type InquiryFilter struct {
+	Status     string
+	UnreadOnly bool
+	PropertyID uint
+}
+
+// CreateInquiry menyimpan inquiry baru dengan OwnerID dari pemilik listing.
+// gorm.ErrRecordNotFound kalau listing tidak ada.
+func (r *InquiryRepository) CreateInquiry(inquiry *models.Inquiry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var property models.Property
+		if err := tx.Select("id", "user_id", "title").First(&property, inquiry.PropertyID).Error; err != nil {
+			return err
+		}
+		inquiry.OwnerID = property.UserID
+		inquiry.Status = models.InquiryStatusNew
+		if err := tx.Create(inquiry).Error; err != nil {
+			return err
+		}
+		inquiry.PropertyTitle = property.Title
+		return nil
+	})
+}
+
+// CountRecentByIP menghitung inquiry dari satu IP sejak waktu tertentu
+func (r *InquiryRepository) CountRecentByIP(ip string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Inquiry{}).Where("ip_address = ? AND created_at >= ?", ip, since).Count(&count).Error
+	return count, err
+}
+
+// HasRecentInquiry true kalau nomor telepon yang sama sudah bertanya tentang listing ini sejak waktu tertentu
+func (r *InquiryRepository) HasRecentInquiry(propertyID uint, phone string, since time.Time) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Inquiry{}).
+		Where("property_id = ? AND phone = ? AND created_at >= ?", propertyID, phone, since).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetOwnerInquiries mengambil inbox pemilik listing dengan pagination, yang terbaru dulu
+func (r *InquiryRepository) GetOwnerInquiries(ownerID uint, filter InquiryFilter, page, limit int) ([]models.Inquiry, int64, error) {
+	query := r.db.Model(&models.Inquiry{}).Where("inquiries.owner_id = ?", ownerID)
+	if filter.Status != "" {
+		query = query.Where("inquiries.status = ?", filter.Status)
+	}
+	if filter.UnreadOnly {
+		query = query.Where("inquiries.read_at IS NULL")
+	}
+	if filter.PropertyID > 0 {
+		query = query.Where("inquiries.property_id = ?", filter.PropertyID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	inquiries := []models.Inquiry{}
+	err := withPropertyTitle(query).
+		Order("inquiries.created_at DESC, inquiries.id DESC").
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&inquiries).Error
+	return inquiries, total, err
+}
+
+// CountUnread menghitung inquiry yang belum dibaca pemilik listing
+func (r *InquiryRepository) CountUnread(ownerID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Inquiry{}).Where("owner_id = ? AND read_at IS NULL", ownerID).Count(&count).Error
+	return count, err
+}
+
+// GetInquiryByID mengambil satu inquiry beserta judul listing-nya
+func (r *InquiryRepository) GetInquiryByID(id uint) (*models.Inquiry, error) {
+	var inquiry models.Inquiry
+	if err := withPropertyTitle(r.db.Model(&models.Inquiry{})).Where("inquiries.id = ?", id).First(&inquiry).Error; err != nil {
+		return nil, err
+	}
+	return &inquiry, nil
+}
+
+// UpdateInquiry menyimpan status, catatan dan status baca inquiry
+func (r *InquiryRepository) UpdateInquiry(inquiry *models.Inquiry) error {
+	if err := r.db.Model(inquiry).Select("status", "notes", "read_at").Updates(inquiry).Error; err != nil {
+		return err
+	}
+	inquiry.Read = inquiry.ReadAt != nil
+	return nil
+}
+
+// MarkRead menandai inquiry sudah dibaca kalau belum
+func (r *InquiryRepository) MarkRead(inquiry *models.Inquiry) error {
+	if inquiry.ReadAt != nil {
+		return nil
+	}
+	now := time.Now()
+	if err := r.db.Model(inquiry).Update("read_at", now).Error; err != nil {
+		return err
+	}
+	inquiry.ReadAt = &now
+	inquiry.Read = true
+	return nil
+}
+
+// withPropertyTitle menambahkan judul listing; LEFT JOIN karena listing bisa sudah dihapus
+func withPropertyTitle(query *gorm.DB) *gorm.DB {
+	return query.Select("inquiries.*, properties.title AS property_title").
+		Joins("LEFT JOIN properties ON properties.id = inquiries.property_id")
+}
@@ -0,0 +1,160 @@
+package database
+
+import (
+	"fmt"
+	"project-zero/internal/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type MediaAssetRepository struct {
+	db *gorm.DB
+}
+
+func NewMediaAssetRepository(db *gorm.DB) *MediaAssetRepository {
+	return &MediaAssetRepository{db: db}
+}
+
+// CreateAsset mencatat file hasil upload beserta rendition-nya
+func (r *MediaAssetRepository) CreateAsset(asset *models.MediaAsset) error {
+	return r.db.Create(asset).Error
+}
+
+// GetUserAsset mengambil asset milik user dengan status tertentu
+func (r *MediaAssetRepository) GetUserAsset(id, userID uint, status string) (*models.MediaAsset, error) {
+	var asset models.MediaAsset
+	if err := r.db.Where("id = ? AND user_id = ? AND status = ?", id, userID, status).First(&asset).Error; err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+// SaveAsset menyimpan perubahan asset
+func (r *MediaAssetRepository) SaveAsset(asset *models.MediaAsset) error {
+	return r.db.Save(asset).Error
+}
+
+// DeleteAsset menghapus catatan asset
+func (r *MediaAssetRepository) DeleteAsset(asset *models.MediaAsset) error {
+	return r.db.Delete(asset).Error
+}
+
+// FindByURL mengambil asset berdasarkan URL file asli, nil kalau URL bukan hasil /upload
+func (r *MediaAssetRepository) FindByURL(url string) (*models.MediaAsset, error) {
+	assets, err := findAssetsByURL(r.db, []string{url})
+	if err != nil {
+		return nil, err
+	}
+	return assets[url], nil
+}
+
+// findAssetsByURL mengambil asset untuk beberapa URL sekaligus, key map adalah URL
+func findAssetsByURL(tx *gorm.DB, urls []string) (map[string]*models.MediaAsset, error) {
+	result := make(map[string]*models.MediaAsset)
+	var filtered []string
+	for _, url := range urls {
+		if url != "" {
+			filtered = append(filtered, url)
+		}
+	}
+	if len(filtered) == 0 {
+		return result, nil
+	}
+
+	var assets []models.MediaAsset
+	if err := tx.Where("url IN ?", filtered).Find(&assets).Error; err != nil {
+		return nil, err
+	}
+	for i := range assets {
+		result[assets[i].URL] = &assets[i]
+	}
+	return result, nil
+}
+
+// attachPhotoThumbnails mengisi PhotoThumbnailPath property dari rendition "card" foto utamanya
+func attachPhotoThumbnails(tx *gorm.DB, properties []models.Property) error {
+	urls := make([]string, 0, len(properties))
+	for _, p := range properties {
+		urls = append(urls, p.PhotoPath)
+	}
+	assets, err := findAssetsByURL(tx, urls)
+	if err != nil {
+		return err
+	}
+	for i := range properties {
+		if asset := assets[properties[i].PhotoPath]; asset != nil {
+			properties[i].PhotoThumbnailPath = asset.Renditions.URL(models.RenditionCard)
+		}
+	}
+	return nil
+}
+
+// assetReference kolom tabel yang menyimpan URL asset. Asset yang URL-nya tidak ada
+// di semua kolom ini dianggap tidak terpakai (orphan).
+type assetReference struct {
+	Table  string
+	Column string
+}
+
+var assetReferences = []assetReference{
+	{Table: "property_photos", Column: "photo_path"},
+	{Table: "properties", Column: "photo_path"},
+	{Table: "message_attachments", Column: "url"},
+}
+
+// referencedExpr kondisi SQL "URL asset dipakai di salah satu tabel referensi"
+func referencedExpr() string {
+	parts := make([]string, len(assetReferences))
+	for i, ref := range assetReferences {
+		parts[i] = fmt.Sprintf("EXISTS (SELECT 1 FROM %s WHERE %s.%s = media_assets.url)", ref.Table, ref.Table, ref.Column)
+	}
+	return "(" + strings.Join(parts, " OR ") + ")"
+}
+
+// refreshAttachment menyamakan flag attached asset dengan pemakaian URL-nya saat ini
+func refreshAttachment(tx *gorm.DB, urls ...string) error {
+	var filtered []string
+	for _, url := range urls {
+		if url != "" {
+			filtered = append(filtered, url)
+		}
+	}
+	if len(filtered) == 0 {
+		return nil
+	}
+
+	referenced := referencedExpr()
+	if err := tx.Model(&models.MediaAsset{}).
+		Where("url IN ? AND attached = ? AND "+referenced, filtered, false).
+		Updates(map[string]interface{}{"attached": true, "detached_at": nil}).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.MediaAsset{}).
+		Where("url IN ? AND attached = ? AND NOT "+referenced, filtered, true).
+		Updates(map[string]interface{}{"attached": false, "detached_at": time.Now()}).Error
+}
+
+// FindOrphans mengambil asset yang tidak dipakai sejak sebelum cutoff: upload langsung yang
+// tidak pernah dikonfirmasi, atau file yang tidak dipakai listing/foto mana pun.
+// Pemakaian dicek ulang ke tabel referensi, flag attached hanya sebagai catatan.
+func (r *MediaAssetRepository) FindOrphans(cutoff time.Time, limit int) ([]models.MediaAsset, error) {
+	assets := []models.MediaAsset{}
+	err := r.db.
+		Where("COALESCE(detached_at, created_at) < ?", cutoff).
+		Where("NOT " + referencedExpr()).
+		Order("id ASC").Limit(limit).Find(&assets).Error
+	return assets, err
+}
+
+// RefreshAttachment menyamakan flag attached asset setelah URL-nya dipasang atau dilepas
+func (r *MediaAssetRepository) RefreshAttachment(urls ...string) error {
+	return refreshAttachment(r.db, urls...)
+}
+
+// DeleteIfOrphan menghapus catatan asset hanya kalau URL-nya memang tidak dipakai, false kalau dilewati
+func (r *MediaAssetRepository) DeleteIfOrphan(asset *models.MediaAsset) (bool, error) {
+	result := r.db.Where("NOT " + referencedExpr()).Delete(asset)
+	return result.RowsAffected > 0, result.Error
+}
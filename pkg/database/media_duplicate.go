@@ -0,0 +1,209 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"project-zero/internal/models"
+	"project-zero/pkg/imaging"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// ErrDuplicatePhoto dikembalikan kalau foto yang sama persis sudah ada di galeri listing
+var ErrDuplicatePhoto = errors.New("Foto yang sama sudah ada di galeri listing ini")
+
+// DuplicateMatch foto listing yang sama atau mirip dengan foto baru
+type DuplicateMatch struct {
+	AssetID      uint   `json:"asset_id"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+	Distance     int    `json:"distance"` // Jarak Hamming perceptual hash, 0 berarti identik
+	Exact        bool   `json:"exact"`
+}
+
+// FindListingDuplicates mencari foto galeri dan foto utama listing yang jarak hash-nya
+// paling jauh maxDistance dari hash foto baru, urut dari yang paling mirip
+func (r *MediaAssetRepository) FindListingDuplicates(propertyID uint, hash uint64, maxDistance int) ([]DuplicateMatch, error) {
+	return findListingDuplicates(r.db, propertyID, hash, maxDistance, true)
+}
+
+// findListingDuplicates membandingkan hash dengan asset yang dipakai listing. withCover ikut
+// membandingkan Property.PhotoPath; AddPhoto mematikannya karena foto utama memang boleh
+// ditambahkan ke galeri.
+func findListingDuplicates(tx *gorm.DB, propertyID uint, hash uint64, maxDistance int, withCover bool) ([]DuplicateMatch, error) {
+	matches := []DuplicateMatch{}
+	if hash == 0 {
+		return matches, nil
+	}
+
+	query := tx.Where("phash <> 0")
+	if withCover {
+		query = query.Where("url IN (SELECT photo_path FROM property_photos WHERE property_id = ?) OR url IN (SELECT photo_path FROM properties WHERE id = ?)", propertyID, propertyID)
+	} else {
+		query = query.Where("url IN (SELECT photo_path FROM property_photos WHERE property_id = ?)", propertyID)
+	}
+	var assets []models.MediaAsset
+	if err := query.Find(&assets).Error; err != nil {
+		return nil, err
+	}
+
+	for _, asset := range assets {
+		distance := imaging.HashDistance(hash, uint64(asset.PHash))
+		if distance > maxDistance {
+			continue
+		}
+		matches = append(matches, DuplicateMatch{
+			AssetID:      asset.ID,
+			URL:          asset.URL,
+			ThumbnailURL: asset.Renditions.URL(models.RenditionThumb),
+			Distance:     distance,
+			Exact:        distance == 0,
+		})
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Distance < matches[j].Distance })
+	return matches, nil
+}
+
+// SharedImage satu foto dalam grup foto yang dipakai beberapa pemilik listing
+type SharedImage struct {
+	AssetID       uint                   `json:"asset_id"`
+	URL           string                 `json:"url"`
+	ThumbnailURL  string                 `json:"thumbnail_url,omitempty"`
+	Renditions    models.ImageRenditions `json:"-"`
+	PHash         int64                  `json:"-" gorm:"column:phash"`
+	PropertyID    uint                   `json:"property_id"`
+	PropertyTitle string                 `json:"property_title"`
+	UserID        uint                   `json:"user_id"`
+}
+
+// SharedImageGroup kumpulan foto identik/mirip yang dipakai listing milik lebih dari satu user
+type SharedImageGroup struct {
+	Hash   string        `json:"hash"` // Hash foto pertama dalam grup (hex)
+	Owners int           `json:"owners"`
+	Images []SharedImage `json:"images"`
+}
+
+// FindSharedAcrossOwners mengelompokkan foto listing yang jarak hash-nya paling jauh maxDistance
+// dan hanya mengembalikan grup yang dipakai lebih dari satu pemilik, diurutkan dari pemilik terbanyak
+// lalu dipotong per halaman. Pasangan kandidat dicari lewat bucket potongan hash (lihat hashBuckets),
+// jadi tidak perlu membandingkan semua foto satu per satu.
+func (r *MediaAssetRepository) FindSharedAcrossOwners(maxDistance, page, limit int) ([]SharedImageGroup, int64, error) {
+	var images []SharedImage
+	err := r.db.Raw(`
+		SELECT ma.id AS asset_id, ma.url, ma.renditions, ma.phash,
+			p.id AS property_id, p.title AS property_title, p.user_id
+		FROM media_assets ma
+		JOIN (
+			SELECT property_id, photo_path FROM property_photos
+			UNION
+			SELECT id, photo_path FROM properties
+		) refs ON refs.photo_path = ma.url
+		JOIN properties p ON p.id = refs.property_id
+		WHERE ma.phash <> 0
+		ORDER BY ma.phash, p.id`).Scan(&images).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Union-find: foto yang mirip secara berantai masuk ke grup yang sama
+	parent := make([]int, len(images))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for _, bucket := range hashBuckets(images, maxDistance) {
+		for a := range bucket {
+			for b := a + 1; b < len(bucket); b++ {
+				i, j := bucket[a], bucket[b]
+				if find(i) == find(j) {
+					continue
+				}
+				if imaging.HashDistance(uint64(images[i].PHash), uint64(images[j].PHash)) <= maxDistance {
+					parent[find(j)] = find(i)
+				}
+			}
+		}
+	}
+
+	members := map[int][]SharedImage{}
+	var roots []int
+	for i := range images {
+		images[i].ThumbnailURL = images[i].Renditions.URL(models.RenditionThumb)
+		root := find(i)
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], images[i])
+	}
+
+	groups := []SharedImageGroup{}
+	for _, root := range roots {
+		owners := map[uint]bool{}
+		for _, image := range members[root] {
+			owners[image.UserID] = true
+		}
+		if len(owners) < 2 {
+			continue
+		}
+		groups = append(groups, SharedImageGroup{
+			Hash:   fmt.Sprintf("%016x", uint64(images[root].PHash)),
+			Owners: len(owners),
+			Images: members[root],
+		})
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Owners > groups[j].Owners })
+
+	total := int64(len(groups))
+	start := (page - 1) * limit
+	if start >= len(groups) {
+		return []SharedImageGroup{}, total, nil
+	}
+	end := start + limit
+	if end > len(groups) {
+		end = len(groups)
+	}
+	return groups[start:end], total, nil
+}
+
+// hashBuckets mengelompokkan index foto yang punya potongan hash identik. Hash 64 bit dibagi jadi
+// maxDistance+1 potongan; dua hash berjarak paling jauh maxDistance pasti sama persis di minimal
+// satu potongan (pigeonhole), jadi pasangan yang mirip selalu ada dalam bucket yang sama.
+// Bucket berisi satu foto dibuang karena tidak punya pasangan.
+func hashBuckets(images []SharedImage, maxDistance int) [][]int {
+	parts := maxDistance + 1
+	if parts > 64 {
+		parts = 64
+	}
+	var buckets [][]int
+	offset := 0
+	for part := 0; part < parts; part++ {
+		width := 64 / parts
+		if part < 64%parts {
+			width++
+		}
+		mask := uint64(1)<<width - 1
+		if width == 64 {
+			mask = ^uint64(0)
+		}
+
+		byValue := map[uint64][]int{}
+		for i, image := range images {
+			value := uint64(image.PHash) >> offset & mask
+			byValue[value] = append(byValue[value], i)
+		}
+		for _, bucket := range byValue {
+			if len(bucket) > 1 {
+				buckets = append(buckets, bucket)
+			}
+		}
+		offset += width
+	}
+	return buckets
+}
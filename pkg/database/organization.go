@@ -0,0 +1,74 @@
+package database
+
+import (
+	"project-zero/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type OrganizationRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+// GetAllOrganizations mengambil semua organisasi urut nama
+func (r *OrganizationRepository) GetAllOrganizations() ([]models.Organization, error) {
+	organizations := []models.Organization{}
+	err := r.db.Order("name ASC").Find(&organizations).Error
+	return organizations, err
+}
+
+// GetOrganizationByID mengambil satu organisasi
+func (r *OrganizationRepository) GetOrganizationByID(id uint) (*models.Organization, error) {
+	var organization models.Organization
+	if err := r.db.First(&organization, id).Error; err != nil {
+		return nil, err
+	}
+	return &organization, nil
+}
+
+// CreateOrganization membuat organisasi baru
+func (r *OrganizationRepository) CreateOrganization(organization *models.Organization) error {
+	return r.db.Create(organization).Error
+}
+
+// UpdateWatermark mengganti pengaturan watermark organisasi. Kolom boolean/kosong ikut
+// disimpan supaya watermark bisa dimatikan.
+func (r *OrganizationRepository) UpdateWatermark(organization *models.Organization) error {
+	return r.db.Model(organization).Select(
+		"watermark_enabled", "watermark_text", "watermark_logo_url",
+		"watermark_position", "watermark_opacity", "watermark_scale",
+	).Updates(organization).Error
+}
+
+// SetUserOrganization memasukkan user ke organisasi, nil untuk mengeluarkan.
+// gorm.ErrRecordNotFound kalau user tidak ada.
+func (r *OrganizationRepository) SetUserOrganization(userID uint, organizationID *uint) error {
+	result := r.db.Model(&models.User{}).Where("id = ?", userID).Update("organization_id", organizationID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetUserWatermark mengambil watermark aktif dari organisasi user,
+// nil kalau user tidak punya organisasi atau watermark organisasinya mati
+func (r *OrganizationRepository) GetUserWatermark(userID uint) (*models.WatermarkSettings, error) {
+	var organizations []models.Organization
+	err := r.db.Where("id = (SELECT organization_id FROM users WHERE id = ? AND deleted_at IS NULL)", userID).
+		Limit(1).Find(&organizations).Error
+	if err != nil || len(organizations) == 0 {
+		return nil, err
+	}
+	watermark := organizations[0].Watermark
+	if !watermark.Enabled || (watermark.Text == "" && watermark.LogoURL == "") {
+		return nil, nil
+	}
+	return &watermark, nil
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"project-zero/internal/models"
 	"project-zero/pkg/utils"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -16,33 +17,130 @@ func NewPropertyRepository(db *gorm.DB) *PropertyRepository {
 }
 
 func (r *PropertyRepository) CreateProperty(property *models.Property) error {
-	return r.db.Create(property).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return createProperty(tx, property)
+	})
 }
 
-func (r *PropertyRepository) GetPropertiesWithFilters(params utils.QueryParams) ([]models.Property, int64, error) {
-	var properties []models.Property
-	var total int64
+// createProperty menyimpan property beserta amenity dan harga awalnya di dalam transaksi tx
+func createProperty(tx *gorm.DB, property *models.Property) error {
+	amenities, err := findAmenities(tx, property.AmenityIDs)
+	if err != nil {
+		return err
+	}
+	property.Amenities = amenities
+	property.OriginalPrice = property.Price
+	property.PriceChangedAt = nil
+	property.VerificationStatus = models.VerificationNone
+	property.LegalVerifiedAt = nil
+	if err := tx.Create(property).Error; err != nil {
+		return err
+	}
+	if err := refreshAttachment(tx, property.PhotoPath); err != nil {
+		return err
+	}
 
-	query := r.db.Model(&models.Property{})
+	// Harga awal juga dicatat supaya riwayat harga lengkap
+	return tx.Create(&models.PropertyPriceHistory{
+		PropertyID: property.ID,
+		OldPrice:   0,
+		NewPrice:   property.Price,
+		ChangedAt:  property.CreatedAt,
+	}).Error
+}
 
+// applyFilters memasang semua filter dari QueryParams ke query.
+// skip berisi nama facet yang filternya tidak dipasang (untuk facet counts).
+func (r *PropertyRepository) applyFilters(query *gorm.DB, params utils.QueryParams, skip string) *gorm.DB {
 	// Filter berdasarkan UserID
 	if params.UserID > 0 {
-		query = query.Where("user_id = ?", params.UserID)
+		query = query.Where("properties.user_id = ?", params.UserID)
 	}
 
 	// Apply filters
-	if params.MinPrice > 0 {
-		query = query.Where("price >= ?", params.MinPrice)
+	// Angka harga hanya sebanding dalam mata uang yang sama, jadi filter harga hanya berlaku
+	// untuk listing dengan mata uang ?currency= (default IDR)
+	monthlyFiltered := (params.MinMonthlyPrice > 0 || params.MaxMonthlyPrice > 0) && skip != "monthly_price"
+	priceFiltered := ((params.MinPrice > 0 || params.MaxPrice > 0) && skip != "price") || monthlyFiltered
+	if priceFiltered && params.Currency == "" {
+		query = query.Where("properties.currency = ?", utils.DefaultCurrency)
+	}
+	if params.MinPrice > 0 && skip != "price" {
+		query = query.Where("properties.price >= ?", params.MinPrice)
+	}
+	if params.MaxPrice > 0 && skip != "price" {
+		query = query.Where("properties.price <= ?", params.MaxPrice)
+	}
+	if params.MinMonthlyPrice > 0 && skip != "monthly_price" {
+		query = query.Where("properties.monthly_price >= ?", params.MinMonthlyPrice)
+	}
+	if params.MaxMonthlyPrice > 0 && skip != "monthly_price" {
+		query = query.Where("properties.monthly_price <= ?", params.MaxMonthlyPrice)
+	}
+	// Harga per bulan hanya ada di listing sewa (listing jual monthly_price = 0)
+	if monthlyFiltered || params.SortBy == "monthly_price" {
+		query = query.Where("properties.listing_type = ?", "WTR")
+	}
+	if params.Currency != "" {
+		query = query.Where("properties.currency = ?", params.Currency)
+	}
+	if params.PricePeriod != "" {
+		query = query.Where("properties.price_period = ?", params.PricePeriod)
+	}
+	if params.ListingType != "" && skip != "listing_type" {
+		query = query.Where("properties.listing_type = ?", params.ListingType)
+	}
+	if params.Bedrooms > 0 && skip != "bedrooms" {
+		query = query.Where("properties.bedrooms >= ?", params.Bedrooms)
+	}
+	if params.Bathrooms > 0 {
+		query = query.Where("properties.bathrooms >= ?", params.Bathrooms)
+	}
+	if params.Certificate != "" && skip != "certificate" {
+		query = query.Where("properties.certificate = ?", params.Certificate)
+	}
+	if params.Verified {
+		query = query.Where("properties.verification_status = ?", models.VerificationVerified)
 	}
-	if params.MaxPrice > 0 {
-		query = query.Where("price <= ?", params.MaxPrice)
+	if params.Location != "" {
+		query = query.Where("properties.address ILIKE ?", "%"+params.Location+"%")
 	}
-	if params.ListingType != "" {
-		query = query.Where("listing_type = ?", params.ListingType)
+	if params.Title != "" {
+		query = query.Where("properties.title ILIKE ?", "%"+params.Title+"%")
 	}
-	if params.Bedrooms > 0 {
-		query = query.Where("bedrooms >= ?", params.Bedrooms)
+	if !params.PriceReducedSince.IsZero() {
+		query = query.Where("properties.id IN (?)", r.db.Model(&models.PropertyPriceHistory{}).
+			Select("property_id").
+			Where("new_price < old_price AND changed_at >= ?", params.PriceReducedSince))
 	}
+	if len(params.Amenities) > 0 && skip != "amenities" {
+		query = query.Where("properties.id IN (?)", r.amenitySubquery(params.Amenities, params.AmenitiesMode))
+	}
+
+	return query
+}
+
+// amenitySubquery mengembalikan subquery property_id yang punya amenity sesuai mode:
+// "all" = harus punya semua slug, "any" = cukup salah satu
+func (r *PropertyRepository) amenitySubquery(slugs []string, mode string) *gorm.DB {
+	sub := r.db.Table("property_amenities").
+		Select("property_amenities.property_id").
+		Joins("JOIN amenities ON amenities.id = property_amenities.amenity_id").
+		Where("amenities.slug IN ?", slugs)
+
+	if mode != "any" {
+		sub = sub.Group("property_amenities.property_id").
+			Having("COUNT(DISTINCT amenities.slug) = ?", len(slugs))
+	}
+
+	return sub
+}
+
+func (r *PropertyRepository) GetPropertiesWithFilters(params utils.QueryParams) ([]models.Property, int64, error) {
+	var properties []models.Property
+	var total int64
+
+	query := r.applyFilters(r.db.Model(&models.Property{}), params, "")
 
 	// Count total
 	query.Count(&total)
@@ -56,21 +154,36 @@ func (r *PropertyRepository) GetPropertiesWithFilters(params utils.QueryParams)
 	if sortOrder == "" {
 		sortOrder = "desc"
 	}
-	query = query.Order(sortBy + " " + sortOrder)
+	query = query.Order("properties." + sortBy + " " + sortOrder)
 
 	// Apply pagination
 	offset := (params.Page - 1) * params.Limit
-	err := query.Limit(params.Limit).Offset(offset).Find(&properties).Error
+	if err := query.Preload("Amenities").Limit(params.Limit).Offset(offset).Find(&properties).Error; err != nil {
+		return nil, 0, err
+	}
 
-	return properties, total, err
+	// Thumbnail foto utama untuk halaman list
+	if err := attachPhotoThumbnails(r.db, properties); err != nil {
+		return nil, 0, err
+	}
+
+	return properties, total, nil
 }
+
 func (r *PropertyRepository) GetPropertyByID(id uint) (*models.Property, error) {
 	var property models.Property
-	err := r.db.First(&property, id).Error
+	err := r.db.Preload("Amenities").First(&property, id).Error
 	if err != nil {
 		return nil, err
 	}
-	return &property, nil
+	properties := []models.Property{property}
+	if err := attachPhotoThumbnails(r.db, properties); err != nil {
+		return nil, err
+	}
+	if properties[0].Media, err = r.GetPropertyMedia(id); err != nil {
+		return nil, err
+	}
+	return &properties[0], nil
 }
 
 func (r *PropertyRepository) UpdateProperty(id uint, property *models.Property) (*models.Property, error) {
@@ -81,34 +194,234 @@ func (r *PropertyRepository) UpdateProperty(id uint, property *models.Property)
 
 	// Update all fields using Updates with map to handle zero values
 	updates := map[string]interface{}{
-		"title":         property.Title,
-		"description":   property.Description,
-		"price":         property.Price,
-		"listing_type":  property.ListingType,
-		"land_size":     property.LandSize,
-		"building_size": property.BuildingSize,
-		"bedrooms":      property.Bedrooms,
-		"bathrooms":     property.Bathrooms,
-		"floors":        property.Floors,
-		"certificate":   property.Certificate,
-		"electricity":   property.Electricity,
-		"water_source":  property.WaterSource,
-		"address":       property.Address,
-		"photo_path":    property.PhotoPath,
-	}
-
-	if err := r.db.Model(&existing).Updates(updates).Error; err != nil {
+		"title":              property.Title,
+		"description":        property.Description,
+		"price":              property.Price,
+		"currency":           property.Currency,
+		"price_negotiable":   property.PriceNegotiable,
+		"price_hidden":       property.PriceHidden,
+		"price_period":       property.PricePeriod,
+		"min_lease_months":   property.MinLeaseMonths,
+		"deposit":            property.Deposit,
+		"included_utilities": property.IncludedUtilities,
+		"monthly_price":      property.MonthlyPrice,
+		"listing_type":       property.ListingType,
+		"land_size":          property.LandSize,
+		"building_size":      property.BuildingSize,
+		"bedrooms":           property.Bedrooms,
+		"bathrooms":          property.Bathrooms,
+		"floors":             property.Floors,
+		"certificate":        property.Certificate,
+		"electricity":        property.Electricity,
+		"water_source":       property.WaterSource,
+		"address":            property.Address,
+		"photo_path":         property.PhotoPath,
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Catat perubahan harga sebelum ditimpa
+		if property.Price != existing.Price {
+			now := time.Now()
+			updates["price_changed_at"] = now
+			if err := tx.Create(&models.PropertyPriceHistory{
+				PropertyID: existing.ID,
+				OldPrice:   existing.Price,
+				NewPrice:   property.Price,
+				ChangedAt:  now,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&existing).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		// Verifikasi legal berlaku untuk sertifikat yang direview, jadi dicabut kalau sertifikat berubah
+		if property.Certificate != existing.Certificate {
+			if err := resetVerification(tx, existing.ID, "Sertifikat listing diubah"); err != nil {
+				return err
+			}
+		}
+
+		// Flag is_cover galeri mengikuti photo_path yang baru
+		if property.PhotoPath != existing.PhotoPath {
+			if err := tx.Model(&models.PropertyPhoto{}).Where("property_id = ?", existing.ID).
+				Update("is_cover", gorm.Expr("kind = ? AND photo_path = ?", models.MediaKindPhoto, property.PhotoPath)).Error; err != nil {
+				return err
+			}
+			if err := refreshAttachment(tx, existing.PhotoPath, property.PhotoPath); err != nil {
+				return err
+			}
+		}
+
+		// Amenity hanya diganti kalau client mengirim amenity_ids
+		if property.AmenityIDs != nil {
+			amenities, err := findAmenities(tx, property.AmenityIDs)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&existing).Association("Amenities").Replace(amenities); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	// Fetch the updated record
-	if err := r.db.First(&existing, id).Error; err != nil {
+	return r.GetPropertyByID(id)
+}
+
+// DeleteProperty menghapus property beserta foto galeri dan dokumennya. File foto tidak langsung
+// dihapus dari storage, asset-nya ditandai tidak dipakai lalu dibersihkan GC. Key file dokumen
+// di storage private dikembalikan supaya dihapus caller setelah transaksi berhasil.
+func (r *PropertyRepository) DeleteProperty(id uint) ([]string, error) {
+	var documentKeys []string
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var urls []string
+		if err := tx.Model(&models.PropertyPhoto{}).Where("property_id = ?", id).Pluck("photo_path", &urls).Error; err != nil {
+			return err
+		}
+		var property models.Property
+		if err := tx.Select("id", "photo_path").First(&property, id).Error; err != nil {
+			return err
+		}
+		urls = append(urls, property.PhotoPath)
+
+		if err := tx.Model(&models.Property{ID: id}).Association("Amenities").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Where("property_id = ?", id).Delete(&models.PropertyPriceHistory{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("property_id = ?", id).Delete(&models.PropertyPhoto{}).Error; err != nil {
+			return err
+		}
+		if err := deleteVerifications(tx, id); err != nil {
+			return err
+		}
+		if err := closeViewings(tx, id); err != nil {
+			return err
+		}
+		if err := deleteFavorites(tx, id); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.PropertyDocument{}).Where("property_id = ?", id).Pluck("storage_key", &documentKeys).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("property_id = ?", id).Delete(&models.PropertyDocument{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.Property{}, id).Error; err != nil {
+			return err
+		}
+		return refreshAttachment(tx, urls...)
+	})
+	return documentKeys, err
+}
+
+// GetPriceHistory mengambil riwayat harga property, urut dari yang paling lama. Listing "hubungi kami"
+// mengembalikan riwayat kosong kecuali untuk pemiliknya.
+func (r *PropertyRepository) GetPriceHistory(propertyID, viewerID uint) ([]models.PropertyPriceHistory, error) {
+	var property models.Property
+	if err := r.db.Select("id", "user_id", "price_hidden").First(&property, propertyID).Error; err != nil {
+		return nil, err
+	}
+
+	history := []models.PropertyPriceHistory{}
+	if property.PriceHidden && property.UserID != viewerID {
+		return history, nil
+	}
+	err := r.db.Where("property_id = ?", propertyID).Order("changed_at ASC, id ASC").Find(&history).Error
+	return history, err
+}
+
+// findAmenities mengambil amenity berdasarkan ID, error kalau ada ID yang tidak terdaftar
+func findAmenities(tx *gorm.DB, ids []uint) ([]models.Amenity, error) {
+	amenities := []models.Amenity{}
+	if len(ids) == 0 {
+		return amenities, nil
+	}
+	if err := tx.Where("id IN ?", ids).Find(&amenities).Error; err != nil {
 		return nil, err
 	}
+	if len(amenities) != len(uniqueIDs(ids)) {
+		return nil, ErrUnknownAmenity
+	}
+	return amenities, nil
+}
+
+func uniqueIDs(ids []uint) map[uint]bool {
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		seen[id] = true
+	}
+	return seen
+}
+
+// GetPropertyPhotos mengambil foto galeri property (tanpa denah, video, 360 dan tour), urut sesuai posisi galeri
+func (r *PropertyRepository) GetPropertyPhotos(propertyID uint) ([]models.PropertyPhoto, error) {
+	photos := []models.PropertyPhoto{}
+	err := r.db.Where("property_id = ? AND kind = ?", propertyID, models.MediaKindPhoto).Order("position ASC, id ASC").Find(&photos).Error
+	return photos, err
+}
+
+// GetPropertyMedia mengambil semua media property dikelompokkan per kind
+func (r *PropertyRepository) GetPropertyMedia(propertyID uint) (*models.PropertyMedia, error) {
+	items := []models.PropertyPhoto{}
+	if err := r.db.Where("property_id = ?", propertyID).Order("position ASC, id ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return models.GroupMedia(items), nil
+}
+
+// GetOwner mengambil user pemilik listing
+func (r *PropertyRepository) GetOwner(userID uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
 
-	return &existing, nil
+// BackfillRentalTerms melengkapi listing WTR lama yang dibuat sebelum ada syarat sewa:
+// price_period diisi "monthly" dan monthly_price dihitung dari price. Aman dijalankan berulang.
+func BackfillRentalTerms(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Property{}).
+			Where("listing_type = ? AND (price_period IS NULL OR price_period = '')", "WTR").
+			Update("price_period", models.PricePeriodMonthly).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Property{}).
+			Where("listing_type = ? AND monthly_price = 0", "WTR").
+			Update("monthly_price", gorm.Expr("CASE price_period WHEN ? THEN price * 30 WHEN ? THEN price / 12 ELSE price END",
+				models.PricePeriodDaily, models.PricePeriodYearly)).Error
+	})
 }
 
-func (r *PropertyRepository) DeleteProperty(id uint) error {
-	return r.db.Delete(&models.Property{}, id).Error
+// BackfillPriceHistory melengkapi listing lama yang dibuat sebelum ada riwayat harga: original_price
+// diisi harga pertama yang diketahui (old_price riwayat paling awal, atau harga sekarang) dan harga awal
+// dicatat di riwayat dengan waktu listing dibuat. Aman dijalankan berulang.
+func BackfillPriceHistory(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			UPDATE properties SET original_price = COALESCE((
+				SELECT h.old_price FROM property_price_histories h
+				WHERE h.property_id = properties.id AND h.old_price > 0
+				ORDER BY h.changed_at ASC, h.id ASC LIMIT 1
+			), price)
+			WHERE original_price = 0`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`
+			INSERT INTO property_price_histories (property_id, old_price, new_price, changed_at)
+			SELECT p.id, 0, p.original_price, p.created_at FROM properties p
+			WHERE NOT EXISTS (
+				SELECT 1 FROM property_price_histories h WHERE h.property_id = p.id AND h.old_price = 0
+			)`).Error
+	})
 }
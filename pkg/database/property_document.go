@@ -0,0 +1,107 @@
+package database
+
+import (
+	"project-zero/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PropertyDocumentRepository struct {
+	db *gorm.DB
+}
+
+func NewPropertyDocumentRepository(db *gorm.DB) *PropertyDocumentRepository {
+	return &PropertyDocumentRepository{db: db}
+}
+
+// CreateDocument mencatat dokumen yang sudah disimpan di storage private
+func (r *PropertyDocumentRepository) CreateDocument(document *models.PropertyDocument) error {
+	if err := r.db.Create(document).Error; err != nil {
+		return err
+	}
+	document.ApplyExpiry(time.Now())
+	return nil
+}
+
+// GetDocumentByID mengambil satu dokumen
+func (r *PropertyDocumentRepository) GetDocumentByID(id uint) (*models.PropertyDocument, error) {
+	var document models.PropertyDocument
+	if err := r.db.First(&document, id).Error; err != nil {
+		return nil, err
+	}
+	document.ApplyExpiry(time.Now())
+	return &document, nil
+}
+
+// GetPropertyDocuments mengambil semua dokumen listing, urut jenis lalu yang terbaru
+func (r *PropertyDocumentRepository) GetPropertyDocuments(propertyID uint) ([]models.PropertyDocument, error) {
+	documents := []models.PropertyDocument{}
+	if err := r.db.Where("property_id = ?", propertyID).Order("type ASC, created_at DESC").Find(&documents).Error; err != nil {
+		return nil, err
+	}
+	applyDocumentExpiry(documents)
+	return documents, nil
+}
+
+// UpdateDocument menyimpan perubahan data dokumen (jenis, nomor, tanggal, catatan), file tidak berubah.
+// Kalau jenis atau nomor dokumen yang dilampirkan di verifikasi berubah, verifikasi listing direset.
+func (r *PropertyDocumentRepository) UpdateDocument(document *models.PropertyDocument) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.PropertyDocument
+		if err := tx.Select("id", "type", "number").First(&existing, document.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(document).
+			Select("type", "title", "number", "issued_at", "expires_at", "notes").
+			Updates(document).Error; err != nil {
+			return err
+		}
+		if existing.Type == document.Type && existing.Number == document.Number {
+			return nil
+		}
+		return resetDocumentVerification(tx, document, "Dokumen verifikasi diubah")
+	})
+	if err != nil {
+		return err
+	}
+	document.ApplyExpiry(time.Now())
+	return nil
+}
+
+// DeleteDocument menghapus catatan dokumen beserta lampirannya di pengajuan verifikasi,
+// file di storage dihapus oleh caller. Verifikasi yang memakai dokumen ini direset.
+func (r *PropertyDocumentRepository) DeleteDocument(document *models.PropertyDocument) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := resetDocumentVerification(tx, document, "Dokumen verifikasi dihapus"); err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM property_verification_documents WHERE property_document_id = ?", document.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(document).Error
+	})
+}
+
+// GetExpiringDocuments mengambil dokumen yang kadaluarsa sebelum `before` (termasuk yang sudah lewat),
+// urut dari yang paling dulu kadaluarsa. ownerID 0 berarti semua listing (admin).
+func (r *PropertyDocumentRepository) GetExpiringDocuments(ownerID uint, before time.Time) ([]models.PropertyDocument, error) {
+	query := r.db.Where("expires_at IS NOT NULL AND expires_at < ?", before)
+	if ownerID > 0 {
+		query = query.Where("property_id IN (SELECT id FROM properties WHERE user_id = ?)", ownerID)
+	}
+
+	documents := []models.PropertyDocument{}
+	if err := query.Order("expires_at ASC, id ASC").Find(&documents).Error; err != nil {
+		return nil, err
+	}
+	applyDocumentExpiry(documents)
+	return documents, nil
+}
+
+func applyDocumentExpiry(documents []models.PropertyDocument) {
+	now := time.Now()
+	for i := range documents {
+		documents[i].ApplyExpiry(now)
+	}
+}
@@ -0,0 +1,38 @@
+package database
+
+import (
+	"project-zero/internal/models"
+	"project-zero/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+// ExportBatchSize jumlah property yang dibaca per batch saat export
+const ExportBatchSize = 200
+
+// ExportProperties membaca semua property yang cocok dengan filter per batch (urut ID),
+// lalu memanggil fn dengan property dan URL foto per property. Tidak memakai pagination,
+// dan hanya satu batch yang ada di memory dalam satu waktu.
+func (r *PropertyRepository) ExportProperties(params utils.QueryParams, fn func(batch []models.Property, photos map[uint][]string) error) error {
+	var batch []models.Property
+	query := r.applyFilters(r.db.Model(&models.Property{}), params, "").Preload("Amenities")
+
+	result := query.FindInBatches(&batch, ExportBatchSize, func(tx *gorm.DB, _ int) error {
+		ids := make([]uint, len(batch))
+		for i, p := range batch {
+			ids[i] = p.ID
+		}
+
+		var photos []models.PropertyPhoto
+		if err := r.db.Where("property_id IN ? AND kind = ?", ids, models.MediaKindPhoto).Order("position ASC, id ASC").Find(&photos).Error; err != nil {
+			return err
+		}
+		photoURLs := make(map[uint][]string, len(batch))
+		for _, photo := range photos {
+			photoURLs[photo.PropertyID] = append(photoURLs[photo.PropertyID], photo.PhotoPath)
+		}
+
+		return fn(batch, photoURLs)
+	})
+	return result.Error
+}
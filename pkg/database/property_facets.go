@@ -0,0 +1,176 @@
+package database
+
+import (
+	"fmt"
+	"project-zero/internal/models"
+	"project-zero/pkg/utils"
+	"strings"
+)
+
+// priceFacetRange adalah satu bucket harga untuk facet "price" dan "monthly_price". Min dan Max inklusif,
+// sama seperti filter min_price/max_price. Max 0 artinya tanpa batas atas.
+type priceFacetRange struct {
+	Min   int64
+	Max   int64
+	Label string
+}
+
+// priceFacetRanges bucket harga jual (WTS). Harga sewa per periode tidak sebanding, jadi tidak ikut dihitung.
+var priceFacetRanges = []priceFacetRange{
+	{Min: 0, Max: 500_000_000, Label: "≤ 500 jt"},
+	{Min: 500_000_001, Max: 1_000_000_000, Label: "500 jt - 1 M"},
+	{Min: 1_000_000_001, Max: 2_000_000_000, Label: "1 M - 2 M"},
+	{Min: 2_000_000_001, Max: 5_000_000_000, Label: "2 M - 5 M"},
+	{Min: 5_000_000_001, Max: 0, Label: "> 5 M"},
+}
+
+// monthlyPriceFacetRanges bucket harga sewa per bulan (WTR), cocok untuk min_monthly_price/max_monthly_price
+var monthlyPriceFacetRanges = []priceFacetRange{
+	{Min: 0, Max: 2_000_000, Label: "≤ 2 jt/bln"},
+	{Min: 2_000_001, Max: 5_000_000, Label: "2 jt - 5 jt/bln"},
+	{Min: 5_000_001, Max: 10_000_000, Label: "5 jt - 10 jt/bln"},
+	{Min: 10_000_001, Max: 25_000_000, Label: "10 jt - 25 jt/bln"},
+	{Min: 25_000_001, Max: 0, Label: "> 25 jt/bln"},
+}
+
+var listingTypeLabels = map[string]string{
+	"WTS": "Dijual",
+	"WTR": "Disewa",
+}
+
+// bedroomBuckets urutan bucket kamar tidur; rumah dengan 5 kamar atau lebih masuk "5+"
+var bedroomBuckets = []string{"0", "1", "2", "3", "4", "5+"}
+
+// GetFacets menghitung semua facet untuk filter yang sedang aktif.
+// Setiap facet memakai semua filter lain kecuali filter dimensinya sendiri,
+// supaya frontend tetap bisa menampilkan pilihan lain (contoh: "WTS (120) / WTR (45)").
+func (r *PropertyRepository) GetFacets(params utils.QueryParams) (map[string][]utils.FacetCount, error) {
+	listingTypes, err := r.countFacet(params, "listing_type", "properties.listing_type")
+	if err != nil {
+		return nil, err
+	}
+	for i := range listingTypes {
+		listingTypes[i].Label = listingTypeLabels[listingTypes[i].Value]
+	}
+
+	certificates, err := r.countFacet(params, "certificate", "properties.certificate")
+	if err != nil {
+		return nil, err
+	}
+
+	bedrooms, err := r.countFacet(params, "bedrooms",
+		"CASE WHEN properties.bedrooms >= 5 THEN '5+' ELSE CAST(properties.bedrooms AS TEXT) END")
+	if err != nil {
+		return nil, err
+	}
+
+	prices, err := r.countFacet(params, "price", priceBucketExpr("properties.price", "WTS", priceFacetRanges))
+	if err != nil {
+		return nil, err
+	}
+
+	monthlyPrices, err := r.countFacet(params, "monthly_price", priceBucketExpr("properties.monthly_price", "WTR", monthlyPriceFacetRanges))
+	if err != nil {
+		return nil, err
+	}
+
+	amenities, err := r.GetAmenityFacets(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string][]utils.FacetCount{
+		"listing_type":  listingTypes,
+		"certificate":   certificates,
+		"bedrooms":      fillBuckets(bedrooms, bedroomBuckets, nil),
+		"price":         fillBuckets(prices, priceBucketValues(priceFacetRanges), priceBucketLabels(priceFacetRanges)),
+		"monthly_price": fillBuckets(monthlyPrices, priceBucketValues(monthlyPriceFacetRanges), priceBucketLabels(monthlyPriceFacetRanges)),
+		"amenities":     amenities,
+	}, nil
+}
+
+// GetAmenityFacets menghitung jumlah property per amenity untuk filter yang sedang aktif.
+// Mode "all" memakai filter amenity yang aktif (drill-down), mode "any" mengabaikannya.
+func (r *PropertyRepository) GetAmenityFacets(params utils.QueryParams) ([]utils.FacetCount, error) {
+	skip := ""
+	if params.AmenitiesMode == "any" {
+		skip = "amenities"
+	}
+	filtered := r.applyFilters(r.db.Model(&models.Property{}).Select("properties.id"), params, skip)
+
+	facets := []utils.FacetCount{}
+	err := r.db.Table("amenities").
+		Select("amenities.slug AS value, amenities.name AS label, COUNT(DISTINCT property_amenities.property_id) AS count").
+		Joins("JOIN property_amenities ON property_amenities.amenity_id = amenities.id").
+		Where("property_amenities.property_id IN (?)", filtered).
+		Group("amenities.id, amenities.slug, amenities.name").
+		Order("count DESC, amenities.name ASC").
+		Scan(&facets).Error
+
+	return facets, err
+}
+
+// countFacet menjalankan GROUP BY expr dengan semua filter kecuali filter milik facet itu sendiri
+func (r *PropertyRepository) countFacet(params utils.QueryParams, facet, expr string) ([]utils.FacetCount, error) {
+	facets := []utils.FacetCount{}
+	err := r.applyFilters(r.db.Model(&models.Property{}), params, facet).
+		Select(expr + " AS value, COUNT(*) AS count").
+		Group("1").
+		Order("1").
+		Scan(&facets).Error
+	return facets, err
+}
+
+// priceBucketExpr membuat ekspresi CASE untuk mengelompokkan column sesuai ranges. Hanya listing
+// listingType dalam Rupiah yang dihitung, listing lain (tipe atau mata uang berbeda) masuk "other"
+// dan tidak ditampilkan.
+func priceBucketExpr(column, listingType string, ranges []priceFacetRange) string {
+	values := priceBucketValues(ranges)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "CASE WHEN properties.listing_type <> '%s' OR properties.currency <> '%s' THEN 'other'", listingType, utils.DefaultCurrency)
+	for i, pr := range ranges {
+		if pr.Max > 0 {
+			fmt.Fprintf(&b, " WHEN %s <= %d THEN '%s'", column, pr.Max, values[i])
+		} else {
+			fmt.Fprintf(&b, " ELSE '%s'", values[i])
+		}
+	}
+	b.WriteString(" END")
+	return b.String()
+}
+
+// priceBucketValues format value "min-max" (max kosong = tanpa batas), cocok untuk filter min/max harga
+func priceBucketValues(ranges []priceFacetRange) []string {
+	values := make([]string, len(ranges))
+	for i, pr := range ranges {
+		if pr.Max > 0 {
+			values[i] = fmt.Sprintf("%d-%d", pr.Min, pr.Max)
+		} else {
+			values[i] = fmt.Sprintf("%d-", pr.Min)
+		}
+	}
+	return values
+}
+
+func priceBucketLabels(ranges []priceFacetRange) map[string]string {
+	labels := make(map[string]string, len(ranges))
+	for i, value := range priceBucketValues(ranges) {
+		labels[value] = ranges[i].Label
+	}
+	return labels
+}
+
+// fillBuckets mengurutkan hasil sesuai order bucket dan mengisi bucket kosong dengan count 0
+func fillBuckets(counts []utils.FacetCount, order []string, labels map[string]string) []utils.FacetCount {
+	byValue := make(map[string]int64, len(counts))
+	for _, fc := range counts {
+		byValue[fc.Value] = fc.Count
+	}
+
+	result := make([]utils.FacetCount, len(order))
+	for i, value := range order {
+		result[i] = utils.FacetCount{Value: value, Label: labels[value], Count: byValue[value]}
+	}
+	return result
+}
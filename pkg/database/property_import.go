@@ -0,0 +1,41 @@
+package database
+
+import (
+	"project-zero/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ImportProperties menyimpan listing hasil import dalam satu transaksi beserta catatan import-nya.
+// properties di-key dengan index baris di record.Rows; kalau satu listing gagal disimpan,
+// tidak ada listing yang masuk.
+func (r *PropertyRepository) ImportProperties(record *models.PropertyImport, properties map[int]*models.Property) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range record.Rows {
+			property, ok := properties[i]
+			if !ok {
+				continue
+			}
+			if err := createProperty(tx, property); err != nil {
+				return err
+			}
+			record.Rows[i].Status = models.ImportRowImported
+			record.Rows[i].PropertyID = property.ID
+			record.ImportedRows++
+		}
+		return tx.Create(record).Error
+	})
+}
+
+// SaveImport menyimpan catatan import tanpa membuat listing (dry-run)
+func (r *PropertyRepository) SaveImport(record *models.PropertyImport) error {
+	return r.db.Create(record).Error
+}
+
+func (r *PropertyRepository) GetImport(id uint) (*models.PropertyImport, error) {
+	var record models.PropertyImport
+	if err := r.db.First(&record, id).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
@@ -0,0 +1,153 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"project-zero/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// MaxPhotosPerProperty batas jumlah foto galeri per listing
+const MaxPhotosPerProperty = 30
+
+// MediaLimits batas jumlah media per listing untuk tiap kind
+var MediaLimits = map[string]int{
+	models.MediaKindPhoto:     MaxPhotosPerProperty,
+	models.MediaKindFloorPlan: 10,
+	models.MediaKindVideo:     3,
+	models.MediaKindPanorama:  10,
+	models.MediaKindTour:      3,
+}
+
+// PanoramaMinRatio rasio lebar:tinggi minimal foto 360 (equirectangular idealnya 2:1)
+const PanoramaMinRatio = 1.9
+
+// ErrPhotoLimitReached dikembalikan kalau galeri listing sudah penuh
+var ErrPhotoLimitReached = fmt.Errorf("Maksimal %d foto per listing", MaxPhotosPerProperty)
+
+// Error kecocokan kind media dengan file-nya
+var (
+	ErrVideoAssetRequired = errors.New("Media video harus memakai URL hasil upload video (/upload/video)")
+	ErrVideoNotAllowed    = errors.New("File video hanya bisa dipasang dengan kind video")
+	ErrNotPanorama        = errors.New("Foto 360 harus panorama equirectangular dengan rasio 2:1")
+	ErrTourIsUpload       = errors.New("Kind tour untuk link virtual tour eksternal, bukan file upload")
+	ErrMediaAssetRequired = errors.New("Media harus memakai URL hasil upload (/upload) milik pemilik listing")
+)
+
+// MediaLimitError dikembalikan kalau jumlah media satu kind (selain foto) di listing sudah penuh
+type MediaLimitError struct {
+	Kind string
+	Max  int
+}
+
+func (e *MediaLimitError) Error() string {
+	return fmt.Sprintf("Maksimal %d media %s per listing", e.Max, e.Kind)
+}
+
+type PropertyPhotoRepository struct {
+	db *gorm.DB
+}
+
+func NewPropertyPhotoRepository(db *gorm.DB) *PropertyPhotoRepository {
+	return &PropertyPhotoRepository{db: db}
+}
+
+// AddPhoto menambahkan media di urutan paling akhir galeri. Selain tour, media wajib MediaAsset
+// hasil upload pemilik listing yang sudah siap; ukuran dan rendition diambil dari asset, bukan dari input client. Foto jadi cover kalau path-nya
+// sama dengan Property.PhotoPath. gorm.ErrRecordNotFound kalau property tidak ada,
+// ErrDuplicatePhoto kalau foto identik sudah ada di galeri.
+func (r *PropertyPhotoRepository) AddPhoto(photo *models.PropertyPhoto) error {
+	if photo.Kind == "" {
+		photo.Kind = models.MediaKindPhoto
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var property models.Property
+		if err := tx.Select("id", "user_id", "photo_path").First(&property, photo.PropertyID).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.PropertyPhoto{}).Where("property_id = ? AND kind = ?", photo.PropertyID, photo.Kind).Count(&count).Error; err != nil {
+			return err
+		}
+		if max := MediaLimits[photo.Kind]; count >= int64(max) {
+			if photo.Kind == models.MediaKindPhoto {
+				return ErrPhotoLimitReached
+			}
+			return &MediaLimitError{Kind: photo.Kind, Max: max}
+		}
+
+		assets, err := findAssetsByURL(tx, []string{photo.PhotoPath})
+		if err != nil {
+			return err
+		}
+		asset := assets[photo.PhotoPath]
+		if err := checkMediaKind(photo.Kind, asset, property.UserID); err != nil {
+			return err
+		}
+		photo.ApplyAsset(asset)
+
+		// Foto yang sama persis (hash identik) tidak boleh masuk dua kali ke galeri
+		if asset != nil {
+			duplicates, err := findListingDuplicates(tx, photo.PropertyID, uint64(asset.PHash), 0, false)
+			if err != nil {
+				return err
+			}
+			if len(duplicates) > 0 {
+				return ErrDuplicatePhoto
+			}
+		}
+
+		if err := tx.Model(&models.PropertyPhoto{}).Where("property_id = ?", photo.PropertyID).
+			Select("COALESCE(MAX(position), -1) + 1").Scan(&photo.Position).Error; err != nil {
+			return err
+		}
+		photo.IsCover = photo.Kind == models.MediaKindPhoto && property.PhotoPath != "" && photo.PhotoPath == property.PhotoPath
+
+		if err := tx.Create(photo).Error; err != nil {
+			return err
+		}
+		return refreshAttachment(tx, photo.PhotoPath)
+	})
+}
+
+// GetPropertyOwnerID mengambil user_id pemilik listing
+func (r *PropertyPhotoRepository) GetPropertyOwnerID(propertyID uint) (uint, error) {
+	var property models.Property
+	if err := r.db.Select("id", "user_id").First(&property, propertyID).Error; err != nil {
+		return 0, err
+	}
+	return property.UserID, nil
+}
+
+// checkMediaKind memastikan file cocok dengan kind media. asset nil berarti URL bukan hasil /upload
+// (link eksternal), hanya boleh untuk tour. Asset upload user lain atau yang belum dikonfirmasi ditolak,
+// supaya file tidak bisa dipakai lintas pemilik dan tetap lewat watermark dan hash duplikat.
+func checkMediaKind(kind string, asset *models.MediaAsset, ownerID uint) error {
+	if kind == models.MediaKindTour {
+		if asset != nil {
+			return ErrTourIsUpload
+		}
+		return nil
+	}
+
+	owned := asset != nil && asset.UserID == ownerID && asset.Status == models.MediaStatusReady
+	switch kind {
+	case models.MediaKindVideo:
+		if !owned || !asset.IsVideo() {
+			return ErrVideoAssetRequired
+		}
+	default:
+		if !owned {
+			return ErrMediaAssetRequired
+		}
+		if asset.IsVideo() {
+			return ErrVideoNotAllowed
+		}
+		if kind == models.MediaKindPanorama && float64(asset.Width) < float64(asset.Height)*PanoramaMinRatio {
+			return ErrNotPanorama
+		}
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package database
+
+import (
+	"errors"
+	"project-zero/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Error alur verifikasi legal
+var (
+	ErrVerificationNoCertificate = errors.New("Lampirkan minimal satu dokumen sertifikat milik listing ini")
+	ErrVerificationDocument      = errors.New("Dokumen tidak ditemukan di listing ini")
+	ErrAlreadyVerified           = errors.New("Listing sudah terverifikasi")
+	ErrVerificationNotPending    = errors.New("Pengajuan verifikasi sudah direview atau dibatalkan")
+	ErrVerificationStale         = errors.New("Sertifikat listing sudah diubah sejak pengajuan, pengajuan dibatalkan")
+)
+
+type PropertyVerificationRepository struct {
+	db *gorm.DB
+}
+
+func NewPropertyVerificationRepository(db *gorm.DB) *PropertyVerificationRepository {
+	return &PropertyVerificationRepository{db: db}
+}
+
+// SubmitVerification membuat pengajuan verifikasi baru dengan dokumen listing yang dilampirkan.
+// Pengajuan lama yang masih pending dibatalkan, status listing jadi pending.
+func (r *PropertyVerificationRepository) SubmitVerification(verification *models.PropertyVerification, documentIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var property models.Property
+		if err := tx.Select("id", "certificate", "verification_status").First(&property, verification.PropertyID).Error; err != nil {
+			return err
+		}
+		if property.VerificationStatus == models.VerificationVerified {
+			return ErrAlreadyVerified
+		}
+
+		var documents []models.PropertyDocument
+		if err := tx.Where("id IN ? AND property_id = ?", documentIDs, property.ID).Find(&documents).Error; err != nil {
+			return err
+		}
+		if len(documents) != len(uniqueIDs(documentIDs)) {
+			return ErrVerificationDocument
+		}
+		hasCertificate := false
+		for _, document := range documents {
+			if document.Type == models.DocumentTypeCertificate {
+				hasCertificate = true
+				break
+			}
+		}
+		if !hasCertificate {
+			return ErrVerificationNoCertificate
+		}
+
+		if err := cancelPendingVerifications(tx, property.ID, "Diganti pengajuan baru"); err != nil {
+			return err
+		}
+
+		verification.ID = 0
+		verification.Certificate = property.Certificate
+		verification.Status = models.VerificationRequestPending
+		verification.Documents = documents
+		if err := tx.Create(verification).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Property{ID: property.ID}).Updates(map[string]interface{}{
+			"verification_status": models.VerificationPending,
+			"legal_verified_at":   nil,
+		}).Error
+	})
+}
+
+// GetVerificationByID mengambil satu pengajuan beserta dokumennya
+func (r *PropertyVerificationRepository) GetVerificationByID(id uint) (*models.PropertyVerification, error) {
+	var verification models.PropertyVerification
+	if err := r.db.Preload("Documents").First(&verification, id).Error; err != nil {
+		return nil, err
+	}
+	applyDocumentExpiry(verification.Documents)
+	return &verification, nil
+}
+
+// GetPropertyVerifications mengambil riwayat pengajuan verifikasi listing, yang terbaru dulu
+func (r *PropertyVerificationRepository) GetPropertyVerifications(propertyID uint) ([]models.PropertyVerification, error) {
+	verifications := []models.PropertyVerification{}
+	if err := r.db.Preload("Documents").Where("property_id = ?", propertyID).
+		Order("created_at DESC, id DESC").Find(&verifications).Error; err != nil {
+		return nil, err
+	}
+	for i := range verifications {
+		applyDocumentExpiry(verifications[i].Documents)
+	}
+	return verifications, nil
+}
+
+// GetVerifications mengambil antrian pengajuan untuk admin, yang paling lama diajukan dulu.
+// status kosong berarti semua status.
+func (r *PropertyVerificationRepository) GetVerifications(status string) ([]models.PropertyVerification, error) {
+	query := r.db.Preload("Documents")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	verifications := []models.PropertyVerification{}
+	if err := query.Order("created_at ASC, id ASC").Find(&verifications).Error; err != nil {
+		return nil, err
+	}
+	for i := range verifications {
+		applyDocumentExpiry(verifications[i].Documents)
+	}
+	return verifications, nil
+}
+
+// ReviewVerification menyetujui atau menolak pengajuan yang masih pending. Kalau sertifikat
+// listing sudah berubah sejak diajukan, pengajuan dibatalkan dan ErrVerificationStale dikembalikan.
+func (r *PropertyVerificationRepository) ReviewVerification(id, reviewerID uint, approve bool, notes string) (*models.PropertyVerification, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var verification models.PropertyVerification
+		if err := tx.First(&verification, id).Error; err != nil {
+			return err
+		}
+		if verification.Status != models.VerificationRequestPending {
+			return ErrVerificationNotPending
+		}
+
+		var property models.Property
+		if err := tx.Select("id", "certificate").First(&property, verification.PropertyID).Error; err != nil {
+			return err
+		}
+		if property.Certificate != verification.Certificate {
+			return ErrVerificationStale
+		}
+
+		now := time.Now()
+		status, propertyStatus := models.VerificationRequestRejected, models.VerificationRejected
+		var verifiedAt *time.Time
+		if approve {
+			status, propertyStatus = models.VerificationRequestApproved, models.VerificationVerified
+			verifiedAt = &now
+		}
+
+		if err := tx.Model(&verification).Updates(map[string]interface{}{
+			"status":       status,
+			"reviewer_id":  reviewerID,
+			"review_notes": notes,
+			"reviewed_at":  now,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Property{ID: property.ID}).Updates(map[string]interface{}{
+			"verification_status": propertyStatus,
+			"legal_verified_at":   verifiedAt,
+		}).Error
+	})
+	if err == ErrVerificationStale {
+		// Pembatalan dilakukan di luar transaksi review yang di-rollback
+		if cancelErr := r.db.Model(&models.PropertyVerification{}).Where("id = ? AND status = ?", id, models.VerificationRequestPending).
+			Updates(map[string]interface{}{"status": models.VerificationRequestCancelled, "review_notes": "Sertifikat listing diubah"}).Error; cancelErr != nil {
+			return nil, cancelErr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.GetVerificationByID(id)
+}
+
+// resetVerification mencabut badge verifikasi listing dan membatalkan pengajuan yang pending,
+// dipanggil saat sertifikat listing atau dokumen yang dilampirkan diubah
+func resetVerification(tx *gorm.DB, propertyID uint, reason string) error {
+	if err := cancelPendingVerifications(tx, propertyID, reason); err != nil {
+		return err
+	}
+	return tx.Model(&models.Property{ID: propertyID}).Updates(map[string]interface{}{
+		"verification_status": models.VerificationNone,
+		"legal_verified_at":   nil,
+	}).Error
+}
+
+// resetDocumentVerification mereset verifikasi listing kalau dokumen dilampirkan di pengajuan
+// yang masih pending, atau di pengajuan yang disetujui selama listing masih terverifikasi
+func resetDocumentVerification(tx *gorm.DB, document *models.PropertyDocument, reason string) error {
+	var property models.Property
+	if err := tx.Select("id", "verification_status").First(&property, document.PropertyID).Error; err != nil {
+		return err
+	}
+	statuses := []string{models.VerificationRequestPending}
+	if property.VerificationStatus == models.VerificationVerified {
+		statuses = append(statuses, models.VerificationRequestApproved)
+	}
+
+	var count int64
+	if err := tx.Model(&models.PropertyVerification{}).
+		Joins("JOIN property_verification_documents pvd ON pvd.property_verification_id = property_verifications.id").
+		Where("pvd.property_document_id = ? AND property_verifications.status IN ?", document.ID, statuses).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+	return resetVerification(tx, property.ID, reason)
+}
+
+func cancelPendingVerifications(tx *gorm.DB, propertyID uint, reason string) error {
+	return tx.Model(&models.PropertyVerification{}).
+		Where("property_id = ? AND status = ?", propertyID, models.VerificationRequestPending).
+		Updates(map[string]interface{}{"status": models.VerificationRequestCancelled, "review_notes": reason}).Error
+}
+
+// deleteVerifications menghapus semua pengajuan listing beserta lampiran dokumennya
+func deleteVerifications(tx *gorm.DB, propertyID uint) error {
+	if err := tx.Exec("DELETE FROM property_verification_documents WHERE property_verification_id IN (SELECT id FROM property_verifications WHERE property_id = ?)", propertyID).Error; err != nil {
+		return err
+	}
+	return tx.Where("property_id = ?", propertyID).Delete(&models.PropertyVerification{}).Error
+}
@@ -0,0 +1,328 @@
+package database
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"project-zero/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Error jadwal viewing
+var (
+	ErrSlotOverlap        = errors.New("Slot bentrok dengan slot lain di listing ini")
+	ErrOutsideSlot        = errors.New("Waktu viewing harus di dalam slot yang disediakan pemilik listing")
+	ErrOwnListingViewing  = errors.New("Tidak bisa booking viewing listing milik sendiri")
+	ErrViewingNotPending  = errors.New("Viewing tidak sedang menunggu jawaban kamu")
+	ErrViewingClosed      = errors.New("Viewing sudah ditolak atau dibatalkan")
+	ErrCalendarFeedAbsent = errors.New("Feed kalender belum dibuat")
+)
+
+// ViewingConflictError jadwal bentrok dengan viewing lain yang sudah confirmed milik agen yang sama
+type ViewingConflictError struct {
+	Conflict models.Viewing
+}
+
+func (e *ViewingConflictError) Error() string {
+	return fmt.Sprintf("Jadwal bentrok dengan viewing lain (%s - %s)",
+		e.Conflict.StartsAt.Format(time.RFC3339), e.Conflict.EndsAt.Format(time.RFC3339))
+}
+
+type ViewingRepository struct {
+	db *gorm.DB
+}
+
+func NewViewingRepository(db *gorm.DB) *ViewingRepository {
+	return &ViewingRepository{db: db}
+}
+
+// CreateSlot menambahkan slot ketersediaan, ErrSlotOverlap kalau bentrok dengan slot lain di listing yang sama
+func (r *ViewingRepository) CreateSlot(slot *models.ViewingSlot) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.ViewingSlot{}).
+			Where("property_id = ? AND starts_at < ? AND ends_at > ?", slot.PropertyID, slot.EndsAt, slot.StartsAt).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrSlotOverlap
+		}
+		return tx.Create(slot).Error
+	})
+}
+
+// GetUpcomingSlots mengambil slot listing yang belum lewat, urut waktu
+func (r *ViewingRepository) GetUpcomingSlots(propertyID uint, now time.Time) ([]models.ViewingSlot, error) {
+	slots := []models.ViewingSlot{}
+	err := r.db.Where("property_id = ? AND ends_at > ?", propertyID, now).Order("starts_at ASC").Find(&slots).Error
+	return slots, err
+}
+
+// GetSlotByID mengambil satu slot
+func (r *ViewingRepository) GetSlotByID(id uint) (*models.ViewingSlot, error) {
+	var slot models.ViewingSlot
+	if err := r.db.First(&slot, id).Error; err != nil {
+		return nil, err
+	}
+	return &slot, nil
+}
+
+// DeleteSlot menghapus slot. Viewing yang sudah dibooking di slot itu tidak ikut berubah.
+func (r *ViewingRepository) DeleteSlot(slot *models.ViewingSlot) error {
+	return r.db.Delete(slot).Error
+}
+
+// RequestViewing membuat permintaan viewing dari pembeli. Waktunya harus di dalam slot listing
+// dan tidak bentrok dengan viewing confirmed agen. gorm.ErrRecordNotFound kalau listing tidak ada.
+func (r *ViewingRepository) RequestViewing(viewing *models.Viewing) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var property models.Property
+		if err := tx.Select("id", "user_id").First(&property, viewing.PropertyID).Error; err != nil {
+			return err
+		}
+		if property.UserID == viewing.BuyerID {
+			return ErrOwnListingViewing
+		}
+		viewing.OwnerID = property.UserID
+		viewing.Status = models.ViewingRequested
+		viewing.RequestedBy = viewing.BuyerID
+
+		if err := checkInsideSlot(tx, viewing.PropertyID, viewing.StartsAt, viewing.EndsAt); err != nil {
+			return err
+		}
+		if err := checkViewingConflict(tx, viewing); err != nil {
+			return err
+		}
+		return tx.Create(viewing).Error
+	})
+}
+
+// GetViewingByID mengambil satu viewing beserta judul dan alamat listing
+func (r *ViewingRepository) GetViewingByID(id uint) (*models.Viewing, error) {
+	var viewing models.Viewing
+	if err := withViewingProperty(r.db.Model(&models.Viewing{})).Where("viewings.id = ?", id).First(&viewing).Error; err != nil {
+		return nil, err
+	}
+	return &viewing, nil
+}
+
+// GetUserViewings mengambil viewing user sebagai pembeli (role "buyer"), pemilik listing ("owner")
+// atau keduanya (role kosong), urut waktu viewing
+func (r *ViewingRepository) GetUserViewings(userID uint, role, status string, page, limit int) ([]models.Viewing, int64, error) {
+	query := r.db.Model(&models.Viewing{})
+	switch role {
+	case "buyer":
+		query = query.Where("viewings.buyer_id = ?", userID)
+	case "owner":
+		query = query.Where("viewings.owner_id = ?", userID)
+	default:
+		query = query.Where("(viewings.buyer_id = ? OR viewings.owner_id = ?)", userID, userID)
+	}
+	if status != "" {
+		query = query.Where("viewings.status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	viewings := []models.Viewing{}
+	err := withViewingProperty(query).
+		Order("viewings.starts_at ASC, viewings.id ASC").
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&viewings).Error
+	return viewings, total, err
+}
+
+// AcceptViewing mengonfirmasi waktu yang diusulkan, dicek ulang terhadap bentrok jadwal agen
+func (r *ViewingRepository) AcceptViewing(viewing *models.Viewing, userID uint) error {
+	if !viewing.CanRespond(userID) {
+		return ErrViewingNotPending
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkViewingConflict(tx, viewing); err != nil {
+			return err
+		}
+		viewing.Status = models.ViewingConfirmed
+		viewing.Reason = ""
+		return tx.Model(viewing).Select("status", "reason").Updates(viewing).Error
+	})
+}
+
+// DeclineViewing menolak waktu yang diusulkan
+func (r *ViewingRepository) DeclineViewing(viewing *models.Viewing, userID uint, reason string) error {
+	if !viewing.CanRespond(userID) {
+		return ErrViewingNotPending
+	}
+	viewing.Status = models.ViewingDeclined
+	viewing.Reason = reason
+	viewing.Sequence++
+	return r.db.Model(viewing).Select("status", "reason", "sequence").Updates(viewing).Error
+}
+
+// CancelViewing membatalkan viewing yang masih requested atau confirmed
+func (r *ViewingRepository) CancelViewing(viewing *models.Viewing, reason string) error {
+	if !viewing.IsOpen() {
+		return ErrViewingClosed
+	}
+	viewing.Status = models.ViewingCancelled
+	viewing.Reason = reason
+	viewing.Sequence++
+	return r.db.Model(viewing).Select("status", "reason", "sequence").Updates(viewing).Error
+}
+
+// RescheduleViewing mengusulkan waktu baru, viewing kembali menunggu jawaban pihak lain.
+// Usulan pembeli harus di dalam slot; pemilik listing bebas memilih waktu.
+func (r *ViewingRepository) RescheduleViewing(viewing *models.Viewing, userID uint, startsAt, endsAt time.Time) error {
+	if !viewing.IsOpen() {
+		return ErrViewingClosed
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if userID == viewing.BuyerID {
+			if err := checkInsideSlot(tx, viewing.PropertyID, startsAt, endsAt); err != nil {
+				return err
+			}
+		}
+		viewing.StartsAt, viewing.EndsAt = startsAt, endsAt
+		if err := checkViewingConflict(tx, viewing); err != nil {
+			return err
+		}
+		viewing.Status = models.ViewingRequested
+		viewing.RequestedBy = userID
+		viewing.Reason = ""
+		viewing.Sequence++
+		return tx.Model(viewing).Select("starts_at", "ends_at", "status", "requested_by", "reason", "sequence").Updates(viewing).Error
+	})
+}
+
+// GetConfirmedViewings mengambil viewing confirmed user (pembeli maupun pemilik) sejak waktu tertentu untuk feed kalender
+func (r *ViewingRepository) GetConfirmedViewings(userID uint, since time.Time) ([]models.Viewing, error) {
+	viewings := []models.Viewing{}
+	err := withViewingProperty(r.db.Model(&models.Viewing{})).
+		Where("(viewings.buyer_id = ? OR viewings.owner_id = ?) AND viewings.status = ? AND viewings.ends_at >= ?",
+			userID, userID, models.ViewingConfirmed, since).
+		Order("viewings.starts_at ASC").
+		Find(&viewings).Error
+	return viewings, err
+}
+
+// GetUsers mengambil user berdasarkan ID, key map adalah ID (untuk nama dan email peserta undangan)
+func (r *ViewingRepository) GetUsers(ids ...uint) (map[uint]models.User, error) {
+	var users []models.User
+	if err := r.db.Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[uint]models.User, len(users))
+	for _, user := range users {
+		result[user.ID] = user
+	}
+	return result, nil
+}
+
+// RotateCalendarFeed membuat token feed kalender baru untuk user, token lama tidak berlaku lagi
+func (r *ViewingRepository) RotateCalendarFeed(userID uint) (*models.CalendarFeed, error) {
+	token, err := newSecretToken()
+	if err != nil {
+		return nil, err
+	}
+	feed := models.CalendarFeed{UserID: userID, Token: token, CreatedAt: time.Now()}
+	err = r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"token", "created_at"}),
+	}).Create(&feed).Error
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// DeleteCalendarFeed mematikan feed kalender user, ErrCalendarFeedAbsent kalau belum pernah dibuat
+func (r *ViewingRepository) DeleteCalendarFeed(userID uint) error {
+	result := r.db.Where("user_id = ?", userID).Delete(&models.CalendarFeed{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCalendarFeedAbsent
+	}
+	return nil
+}
+
+// FindCalendarFeed mengambil feed berdasarkan token
+func (r *ViewingRepository) FindCalendarFeed(token string) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	if err := r.db.Where("token = ?", token).First(&feed).Error; err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// checkInsideSlot memastikan rentang waktu ada di dalam satu slot listing
+func checkInsideSlot(tx *gorm.DB, propertyID uint, startsAt, endsAt time.Time) error {
+	var count int64
+	if err := tx.Model(&models.ViewingSlot{}).
+		Where("property_id = ? AND starts_at <= ? AND ends_at >= ?", propertyID, startsAt, endsAt).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrOutsideSlot
+	}
+	return nil
+}
+
+// checkViewingConflict mencari viewing confirmed lain milik agen yang sama (di listing mana pun)
+// yang waktunya bertabrakan. Advisory lock per agen mencegah dua konfirmasi bersamaan lolos.
+func checkViewingConflict(tx *gorm.DB, viewing *models.Viewing) error {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", int64(viewing.OwnerID)).Error; err != nil {
+		return err
+	}
+
+	var conflicts []models.Viewing
+	err := tx.Where("owner_id = ? AND status = ? AND id <> ? AND starts_at < ? AND ends_at > ?",
+		viewing.OwnerID, models.ViewingConfirmed, viewing.ID, viewing.EndsAt, viewing.StartsAt).
+		Order("starts_at ASC").Limit(1).Find(&conflicts).Error
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return &ViewingConflictError{Conflict: conflicts[0]}
+	}
+	return nil
+}
+
+// closeViewings menghapus slot listing dan membatalkan viewing yang masih berjalan saat listing dihapus.
+// Riwayat viewing tetap disimpan supaya undangan di kalender peserta ikut batal.
+func closeViewings(tx *gorm.DB, propertyID uint) error {
+	if err := tx.Where("property_id = ?", propertyID).Delete(&models.ViewingSlot{}).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.Viewing{}).
+		Where("property_id = ? AND status IN ?", propertyID, []string{models.ViewingRequested, models.ViewingConfirmed}).
+		Updates(map[string]interface{}{
+			"status":   models.ViewingCancelled,
+			"reason":   "Listing dihapus",
+			"sequence": gorm.Expr("sequence + 1"),
+		}).Error
+}
+
+// withViewingProperty menambahkan judul dan alamat listing, LEFT JOIN karena listing bisa sudah dihapus
+func withViewingProperty(query *gorm.DB) *gorm.DB {
+	return query.Select("viewings.*, properties.title AS property_title, properties.address AS property_address").
+		Joins("LEFT JOIN properties ON properties.id = viewings.property_id")
+}
+
+// newSecretToken token acak 64 karakter hex untuk link rahasia (feed kalender, share shortlist)
+func newSecretToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"net/http"
+	"project-zero/internal/models"
+	"project-zero/pkg/database"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+type AmenityHandler struct {
+	repo *database.AmenityRepository
+}
+
+// NewAmenityHandler membuat instance baru AmenityHandler
+func NewAmenityHandler(repo *database.AmenityRepository) *AmenityHandler {
+	return &AmenityHandler{repo: repo}
+}
+
+// GetAllAmenities mengambil seluruh katalog amenity
+func (h *AmenityHandler) GetAllAmenities(c *gin.Context) {
+	amenities, err := h.repo.GetAllAmenities()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal mengambil data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": amenities})
+}
+
+// CreateAmenity menambahkan amenity baru ke katalog (admin only)
+func (h *AmenityHandler) CreateAmenity(c *gin.Context) {
+	var input models.Amenity
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validasi gagal",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if !normalizeAmenity(&input) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Slug tidak valid"})
+		return
+	}
+
+	if err := h.repo.CreateAmenity(&input); err != nil {
+		if err == database.ErrAmenitySlugTaken {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal menyimpan data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": input})
+}
+
+// UpdateAmenity mengupdate amenity di katalog (admin only)
+func (h *AmenityHandler) UpdateAmenity(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return
+	}
+
+	var input models.Amenity
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validasi gagal",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if !normalizeAmenity(&input) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Slug tidak valid"})
+		return
+	}
+
+	amenity, err := h.repo.UpdateAmenity(uint(id), &input)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		} else if err == database.ErrAmenitySlugTaken {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Gagal mengupdate data",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": amenity})
+}
+
+// DeleteAmenity menghapus amenity dari katalog (admin only)
+func (h *AmenityHandler) DeleteAmenity(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return
+	}
+
+	if err := h.repo.DeleteAmenity(uint(id)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Gagal menghapus data",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Amenity berhasil dihapus"})
+}
+
+// normalizeAmenity merapikan name/category dan membuat slug dari name kalau kosong
+func normalizeAmenity(amenity *models.Amenity) bool {
+	amenity.Name = strings.TrimSpace(amenity.Name)
+	amenity.Category = strings.TrimSpace(amenity.Category)
+
+	slug := amenity.Slug
+	if slug == "" {
+		slug = amenity.Name
+	}
+	amenity.Slug = strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(slug), "-"), "-")
+
+	return amenity.Slug != ""
+}
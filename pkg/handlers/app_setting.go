@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"project-zero/pkg/database"
+	"project-zero/pkg/imaging"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SettingHandler struct {
+	repo *database.SettingRepository
+}
+
+// NewSettingHandler membuat instance baru SettingHandler
+func NewSettingHandler(repo *database.SettingRepository) *SettingHandler {
+	return &SettingHandler{repo: repo}
+}
+
+type UpdateMetadataAllowlistRequest struct {
+	Tags []string `json:"tags" binding:"required"`
+}
+
+// GetMetadataAllowlist menampilkan tag EXIF yang dipertahankan saat upload foto (admin only)
+func (h *SettingHandler) GetMetadataAllowlist(c *gin.Context) {
+	tags, err := h.repo.GetMetadataAllowlist()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal mengambil data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"tags":           tags,
+		"available_tags": imaging.AllowedMetadataTags(),
+	}})
+}
+
+// UpdateMetadataAllowlist mengganti tag EXIF yang dipertahankan saat upload foto (admin only).
+// GPS tidak bisa masuk allowlist, hanya tag teks seperti Copyright dan Artist.
+func (h *SettingHandler) UpdateMetadataAllowlist(c *gin.Context) {
+	var req UpdateMetadataAllowlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validasi gagal",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	seen := map[string]bool{}
+	tags := []string{}
+	for _, tag := range req.Tags {
+		if !imaging.IsAllowedMetadataTag(tag) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validasi gagal",
+				"details": fmt.Sprintf("tag %q tidak bisa dipertahankan, pilihan: %v", tag, imaging.AllowedMetadataTags()),
+			})
+			return
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	if err := h.repo.SetMetadataAllowlist(tags); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal menyimpan data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"tags": tags}})
+}
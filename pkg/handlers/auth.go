@@ -40,6 +40,7 @@ type UserResponse struct {
 	ID    uint   `json:"id"`
 	Name  string `json:"name"`
 	Email string `json:"email"`
+	Role  string `json:"role"`
 }
 
 // Signup - Register user baru
@@ -69,6 +70,7 @@ func (h *AuthHandler) Signup(c *gin.Context) {
 		Name:     req.Name,
 		Email:    req.Email,
 		Password: string(hashedPassword),
+		Role:     models.RoleUser,
 	}
 
 	if err := h.db.Create(&user).Error; err != nil {
@@ -89,6 +91,7 @@ func (h *AuthHandler) Signup(c *gin.Context) {
 			ID:    user.ID,
 			Name:  user.Name,
 			Email: user.Email,
+			Role:  user.Role,
 		},
 	})
 }
@@ -127,6 +130,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 			ID:    user.ID,
 			Name:  user.Name,
 			Email: user.Email,
+			Role:  user.Role,
 		},
 	})
 }
@@ -149,6 +153,7 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 		ID:    user.ID,
 		Name:  user.Name,
 		Email: user.Email,
+		Role:  user.Role,
 	})
 }
 
@@ -210,3 +215,24 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// AdminMiddleware - Middleware untuk route khusus admin, dipasang setelah AuthMiddleware
+func (h *AuthHandler) AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("userID")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		var user models.User
+		if err := h.db.Select("id", "role").First(&user, userID).Error; err != nil || user.Role != models.RoleAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Akses khusus admin"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
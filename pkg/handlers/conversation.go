@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"net/http"
+	"project-zero/internal/models"
+	"project-zero/pkg/database"
+	"project-zero/pkg/utils"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ConversationHandler struct {
+	repo *database.ConversationRepository
+}
+
+// NewConversationHandler membuat instance baru ConversationHandler
+func NewConversationHandler(repo *database.ConversationRepository) *ConversationHandler {
+	return &ConversationHandler{repo: repo}
+}
+
+// SendMessageRequest isi pesan. Attachments berisi URL hasil /upload atau /upload/video milik pengirim.
+type SendMessageRequest struct {
+	Body        string   `json:"body" binding:"max=5000"`
+	Attachments []string `json:"attachments" binding:"max=5,dive,required"`
+}
+
+// StartConversation memulai (atau melanjutkan) percakapan pembeli dengan pemilik listing
+// dan mengirim pesan pertama
+func (h *ConversationHandler) StartConversation(c *gin.Context) {
+	propertyID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return
+	}
+	req, ok := bindMessage(c)
+	if !ok {
+		return
+	}
+
+	userID := currentUserID(c)
+	conversation, err := h.repo.FindOrCreateConversation(uint(propertyID), userID)
+	if err != nil {
+		switch err {
+		case gorm.ErrRecordNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		case database.ErrOwnListingConversation:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan data", "details": err.Error()})
+		}
+		return
+	}
+
+	message, ok := h.send(c, conversation, userID, req)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": gin.H{"conversation": conversation, "message": message}})
+}
+
+// GetConversations mengambil thread user yang login, sebagai pembeli maupun pemilik listing.
+// Filter ?property_id=, pagination ?page=&limit=
+func (h *ConversationHandler) GetConversations(c *gin.Context) {
+	var propertyID uint
+	if raw := c.Query("property_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property_id"})
+			return
+		}
+		propertyID = uint(id)
+	}
+	page, limit := utils.ParsePagination(c)
+
+	userID := currentUserID(c)
+	conversations, total, err := h.repo.GetUserConversations(userID, propertyID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+	unread, err := h.repo.CountUnread(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": conversations,
+		"pagination": utils.PaginationMetadata{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: utils.CalculateTotalPages(total, limit),
+		},
+		"unread": unread,
+	})
+}
+
+// GetUnreadCount jumlah pesan belum dibaca user yang login di semua thread
+func (h *ConversationHandler) GetUnreadCount(c *gin.Context) {
+	unread, err := h.repo.CountUnread(currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"unread": unread}})
+}
+
+// GetMessages mengambil pesan thread (terbaru dulu, pagination ?page=&limit=) dan menandai
+// pesan dari lawan bicara sudah dibaca
+func (h *ConversationHandler) GetMessages(c *gin.Context) {
+	conversation, ok := h.participantConversation(c)
+	if !ok {
+		return
+	}
+	userID := currentUserID(c)
+	if err := h.repo.MarkRead(conversation.ID, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan data", "details": err.Error()})
+		return
+	}
+	conversation.UnreadCount = 0
+
+	page, limit := utils.ParsePagination(c)
+	messages, total, err := h.repo.GetMessages(conversation.ID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":         messages,
+		"conversation": conversation,
+		"pagination": utils.PaginationMetadata{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: utils.CalculateTotalPages(total, limit),
+		},
+	})
+}
+
+// SendMessage mengirim pesan ke thread yang sudah ada
+func (h *ConversationHandler) SendMessage(c *gin.Context) {
+	conversation, ok := h.participantConversation(c)
+	if !ok {
+		return
+	}
+	req, ok := bindMessage(c)
+	if !ok {
+		return
+	}
+
+	message, ok := h.send(c, conversation, currentUserID(c), req)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": message})
+}
+
+// send menyimpan pesan, response error langsung dikirim kalau gagal
+func (h *ConversationHandler) send(c *gin.Context, conversation *models.Conversation, senderID uint, req *SendMessageRequest) (*models.Message, bool) {
+	message := models.Message{SenderID: senderID, Body: req.Body}
+	if err := h.repo.AddMessage(conversation, &message, req.Attachments); err != nil {
+		if err == database.ErrAttachmentNotFound {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": err.Error()})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan data", "details": err.Error()})
+		return nil, false
+	}
+	return &message, true
+}
+
+// bindMessage membaca isi pesan, minimal teks atau satu lampiran
+func bindMessage(c *gin.Context) (*SendMessageRequest, bool) {
+	var req SendMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validasi gagal",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+	req.Body = strings.TrimSpace(req.Body)
+	if req.Body == "" && len(req.Attachments) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": "Pesan tidak boleh kosong"})
+		return nil, false
+	}
+	return &req, true
+}
+
+// participantConversation mengambil thread dari param :id. Thread di mana user bukan pembeli
+// maupun pemilik listing dianggap tidak ada.
+func (h *ConversationHandler) participantConversation(c *gin.Context) (*models.Conversation, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return nil, false
+	}
+
+	userID := currentUserID(c)
+	conversation, err := h.repo.GetConversation(uint(id), userID)
+	if err == gorm.ErrRecordNotFound || (err == nil && !conversation.HasParticipant(userID)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return nil, false
+	}
+	return conversation, true
+}
+
+// currentUserID user yang login dari AuthMiddleware, 0 kalau tidak ada
+func currentUserID(c *gin.Context) uint {
+	userID, _ := c.Get("userID")
+	uid, _ := userID.(uint)
+	return uid
+}
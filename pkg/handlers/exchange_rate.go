@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"project-zero/internal/models"
+	"project-zero/pkg/database"
+	"project-zero/pkg/utils"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type ExchangeRateHandler struct {
+	repo *database.ExchangeRateRepository
+}
+
+// NewExchangeRateHandler membuat instance baru ExchangeRateHandler
+func NewExchangeRateHandler(repo *database.ExchangeRateRepository) *ExchangeRateHandler {
+	return &ExchangeRateHandler{repo: repo}
+}
+
+type UpsertExchangeRatesRequest struct {
+	Rates []models.ExchangeRate `json:"rates" binding:"required,min=1,dive"`
+}
+
+// GetExchangeRates mengambil semua kurs yang tersimpan
+func (h *ExchangeRateHandler) GetExchangeRates(c *gin.Context) {
+	rates, err := h.repo.GetAllRates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal mengambil data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": rates})
+}
+
+// UpsertExchangeRates menyimpan/menimpa kurs dari JSON (admin only)
+func (h *ExchangeRateHandler) UpsertExchangeRates(c *gin.Context) {
+	var req UpsertExchangeRatesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validasi gagal",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	h.saveRates(c, req.Rates)
+}
+
+// ImportExchangeRatesCSV import kurs dari file CSV dengan kolom: currency,idr_per_unit (admin only)
+func (h *ExchangeRateHandler) ImportExchangeRatesCSV(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal ambil file"})
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal membuka file"})
+		return
+	}
+	defer src.Close()
+
+	reader := csv.NewReader(src)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var rates []models.ExchangeRate
+	var rowErrors []string
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "File CSV tidak valid", "details": err.Error()})
+			return
+		}
+
+		// Baris header boleh ada, boleh tidak
+		if line == 1 && len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), "currency") {
+			continue
+		}
+
+		rate, err := parseExchangeRateRecord(record)
+		if err != nil {
+			rowErrors = append(rowErrors, fmt.Sprintf("baris %d: %v", line, err))
+			continue
+		}
+		rates = append(rates, rate)
+	}
+
+	if len(rowErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": rowErrors})
+		return
+	}
+	if len(rates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File CSV kosong"})
+		return
+	}
+
+	h.saveRates(c, rates)
+}
+
+// DeleteExchangeRate menghapus kurs suatu mata uang (admin only)
+func (h *ExchangeRateHandler) DeleteExchangeRate(c *gin.Context) {
+	currency := strings.ToUpper(c.Param("currency"))
+
+	if err := h.repo.DeleteRate(currency); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Gagal menghapus data",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Kurs berhasil dihapus"})
+}
+
+func (h *ExchangeRateHandler) saveRates(c *gin.Context, rates []models.ExchangeRate) {
+	for i := range rates {
+		rates[i].ID = 0
+		rates[i].Currency = strings.ToUpper(rates[i].Currency)
+		if rates[i].Currency == utils.DefaultCurrency {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": "kurs IDR selalu 1, tidak perlu disimpan"})
+			return
+		}
+	}
+
+	if err := h.repo.UpsertRates(rates); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal menyimpan data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	saved, err := h.repo.GetAllRates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal mengambil data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": saved, "imported": len(rates)})
+}
+
+func parseExchangeRateRecord(record []string) (models.ExchangeRate, error) {
+	if len(record) < 2 {
+		return models.ExchangeRate{}, fmt.Errorf("butuh 2 kolom: currency,idr_per_unit")
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(record[0]))
+	if !currencyCodePattern.MatchString(currency) {
+		return models.ExchangeRate{}, fmt.Errorf("kode mata uang %q tidak valid", record[0])
+	}
+
+	rate, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+	if err != nil || rate <= 0 {
+		return models.ExchangeRate{}, fmt.Errorf("kurs %q tidak valid", record[1])
+	}
+
+	return models.ExchangeRate{Currency: currency, IDRPerUnit: rate}, nil
+}
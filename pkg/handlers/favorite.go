@@ -0,0 +1,363 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"project-zero/internal/models"
+	"project-zero/pkg/database"
+	"project-zero/pkg/utils"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type FavoriteHandler struct {
+	repo  *database.FavoriteRepository
+	rates *database.ExchangeRateRepository
+}
+
+// NewFavoriteHandler membuat instance baru FavoriteHandler
+func NewFavoriteHandler(repo *database.FavoriteRepository, rates *database.ExchangeRateRepository) *FavoriteHandler {
+	return &FavoriteHandler{repo: repo, rates: rates}
+}
+
+// ShortlistItemRequest listing yang ditambahkan ke shortlist
+type ShortlistItemRequest struct {
+	PropertyID uint   `json:"property_id" binding:"required"`
+	Note       string `json:"note" binding:"max=500"`
+}
+
+// AddFavorite menyimpan listing ke favorit user yang login
+func (h *FavoriteHandler) AddFavorite(c *gin.Context) {
+	propertyID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return
+	}
+
+	if err := h.repo.AddFavorite(currentUserID(c), uint(propertyID)); err != nil {
+		switch err {
+		case gorm.ErrRecordNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		case database.ErrOwnListingFavorite:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan data", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"property_id": propertyID, "is_favorite": true}})
+}
+
+// RemoveFavorite menghapus listing dari favorit user yang login
+func (h *FavoriteHandler) RemoveFavorite(c *gin.Context) {
+	propertyID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return
+	}
+
+	if err := h.repo.RemoveFavorite(currentUserID(c), uint(propertyID)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus data", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"property_id": propertyID, "is_favorite": false}})
+}
+
+// GetFavorites mengambil listing favorit user yang login, pagination ?page=&limit=
+func (h *FavoriteHandler) GetFavorites(c *gin.Context) {
+	page, limit := utils.ParsePagination(c)
+	userID := currentUserID(c)
+
+	favorites, total, err := h.repo.GetFavorites(userID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+
+	properties := make([]*models.Property, len(favorites))
+	for i := range favorites {
+		properties[i] = favorites[i].Property
+	}
+	if err := h.presentProperties(c, properties, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": favorites,
+		"pagination": utils.PaginationMetadata{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: utils.CalculateTotalPages(total, limit),
+		},
+	})
+}
+
+// CreateShortlist membuat shortlist baru
+func (h *FavoriteHandler) CreateShortlist(c *gin.Context) {
+	var input models.Shortlist
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": err.Error()})
+		return
+	}
+	shortlist := models.Shortlist{
+		UserID:      currentUserID(c),
+		Name:        strings.TrimSpace(input.Name),
+		Description: strings.TrimSpace(input.Description),
+	}
+
+	if err := h.repo.CreateShortlist(&shortlist); err != nil {
+		if err == database.ErrShortlistLimit {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validasi gagal",
+				"details": fmt.Sprintf("%s (%d)", err.Error(), models.MaxShortlists),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan data", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": shortlist})
+}
+
+// GetShortlists mengambil semua shortlist user yang login
+func (h *FavoriteHandler) GetShortlists(c *gin.Context) {
+	shortlists, err := h.repo.GetUserShortlists(currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+	for i := range shortlists {
+		applyShareURL(&shortlists[i])
+	}
+	c.JSON(http.StatusOK, gin.H{"data": shortlists})
+}
+
+// GetShortlist mengambil shortlist beserta listing di dalamnya
+func (h *FavoriteHandler) GetShortlist(c *gin.Context) {
+	shortlist, ok := h.ownShortlist(c)
+	if !ok {
+		return
+	}
+	h.respondWithItems(c, shortlist, currentUserID(c))
+}
+
+// UpdateShortlist mengganti nama dan deskripsi shortlist
+func (h *FavoriteHandler) UpdateShortlist(c *gin.Context) {
+	shortlist, ok := h.ownShortlist(c)
+	if !ok {
+		return
+	}
+
+	var input models.Shortlist
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": err.Error()})
+		return
+	}
+	shortlist.Name = strings.TrimSpace(input.Name)
+	shortlist.Description = strings.TrimSpace(input.Description)
+
+	if err := h.repo.UpdateShortlist(shortlist); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan data", "details": err.Error()})
+		return
+	}
+	applyShareURL(shortlist)
+	c.JSON(http.StatusOK, gin.H{"data": shortlist})
+}
+
+// DeleteShortlist menghapus shortlist, link share-nya ikut mati
+func (h *FavoriteHandler) DeleteShortlist(c *gin.Context) {
+	shortlist, ok := h.ownShortlist(c)
+	if !ok {
+		return
+	}
+	if err := h.repo.DeleteShortlist(shortlist); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus data", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Shortlist berhasil dihapus"})
+}
+
+// AddShortlistItem menambahkan listing ke shortlist. Kalau listing sudah ada, catatannya diperbarui.
+func (h *FavoriteHandler) AddShortlistItem(c *gin.Context) {
+	shortlist, ok := h.ownShortlist(c)
+	if !ok {
+		return
+	}
+
+	var req ShortlistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": err.Error()})
+		return
+	}
+
+	item := models.ShortlistItem{PropertyID: req.PropertyID, Note: strings.TrimSpace(req.Note)}
+	if err := h.repo.AddShortlistItem(shortlist, &item); err != nil {
+		switch err {
+		case gorm.ErrRecordNotFound:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": "property_id tidak ditemukan"})
+		case database.ErrShortlistFull:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validasi gagal",
+				"details": fmt.Sprintf("%s (maksimal %d listing)", err.Error(), models.MaxShortlistItems),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan data", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": item})
+}
+
+// RemoveShortlistItem menghapus listing dari shortlist
+func (h *FavoriteHandler) RemoveShortlistItem(c *gin.Context) {
+	shortlist, ok := h.ownShortlist(c)
+	if !ok {
+		return
+	}
+	propertyID, err := strconv.ParseUint(c.Param("property_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property_id"})
+		return
+	}
+
+	if err := h.repo.RemoveShortlistItem(shortlist, uint(propertyID)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus data", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Listing berhasil dihapus dari shortlist"})
+}
+
+// ShareShortlist membuat (atau mengganti) link share shortlist. Link lama langsung tidak berlaku.
+func (h *FavoriteHandler) ShareShortlist(c *gin.Context) {
+	shortlist, ok := h.ownShortlist(c)
+	if !ok {
+		return
+	}
+	if err := h.repo.ShareShortlist(shortlist); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan data", "details": err.Error()})
+		return
+	}
+	applyShareURL(shortlist)
+	c.JSON(http.StatusOK, gin.H{"data": shortlist})
+}
+
+// UnshareShortlist mematikan link share shortlist
+func (h *FavoriteHandler) UnshareShortlist(c *gin.Context) {
+	shortlist, ok := h.ownShortlist(c)
+	if !ok {
+		return
+	}
+	if err := h.repo.UnshareShortlist(shortlist); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan data", "details": err.Error()})
+		return
+	}
+	shortlist.ShareURL = ""
+	c.JSON(http.StatusOK, gin.H{"data": shortlist})
+}
+
+// GetSharedShortlist menampilkan shortlist yang dibagikan (PUBLIC, akses lewat token di link).
+// Harga "hubungi kami" tetap disembunyikan.
+func (h *FavoriteHandler) GetSharedShortlist(c *gin.Context) {
+	shortlist, err := h.repo.GetShortlistByToken(c.Param("token"))
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+	h.respondWithItems(c, shortlist, 0)
+}
+
+// respondWithItems memuat listing shortlist lalu mengirimnya sebagai response
+func (h *FavoriteHandler) respondWithItems(c *gin.Context, shortlist *models.Shortlist, viewerID uint) {
+	if err := h.repo.LoadItems(shortlist); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+
+	properties := make([]*models.Property, len(shortlist.Items))
+	for i := range shortlist.Items {
+		properties[i] = shortlist.Items[i].Property
+	}
+	if err := h.presentProperties(c, properties, viewerID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+	if viewerID != 0 {
+		applyShareURL(shortlist)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": shortlist})
+}
+
+// presentProperties mengisi tampilan harga (?currencies=) dan flag favorit listing hasil preload
+func (h *FavoriteHandler) presentProperties(c *gin.Context, properties []*models.Property, viewerID uint) error {
+	list := make([]models.Property, 0, len(properties))
+	for _, p := range properties {
+		if p != nil {
+			list = append(list, *p)
+		}
+	}
+
+	if err := applyPriceDisplay(h.rates, list, utils.ParseCurrencies(c.Query("currencies")), false, viewerID); err != nil {
+		return err
+	}
+	if err := applyFavorites(h.repo, list, viewerID); err != nil {
+		return err
+	}
+
+	i := 0
+	for _, p := range properties {
+		if p != nil {
+			*p = list[i]
+			i++
+		}
+	}
+	return nil
+}
+
+// ownShortlist mengambil shortlist dari param :id. Shortlist milik user lain dianggap tidak ada.
+func (h *FavoriteHandler) ownShortlist(c *gin.Context) (*models.Shortlist, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return nil, false
+	}
+
+	shortlist, err := h.repo.GetShortlistByID(uint(id))
+	if err == gorm.ErrRecordNotFound || (err == nil && shortlist.UserID != currentUserID(c)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return nil, false
+	}
+	return shortlist, true
+}
+
+// applyShareURL mengisi link share untuk pemilik shortlist yang sudah dibagikan
+func applyShareURL(shortlist *models.Shortlist) {
+	shortlist.ShareURL = ""
+	if shortlist.ShareToken != nil {
+		shortlist.ShareURL = fmt.Sprintf("%s/shared/shortlists/%s", publicBaseURL(), *shortlist.ShareToken)
+	}
+}
@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"net/http"
+	"project-zero/internal/models"
+	"project-zero/pkg/database"
+	"project-zero/pkg/utils"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Proteksi spam inquiry
+const (
+	MaxInquiriesPerIP     = 5 // Inquiry maksimal dari satu IP dalam InquiryRateWindow
+	InquiryRateWindow     = time.Hour
+	InquiryRepeatWindow   = 24 * time.Hour // Nomor yang sama tidak bisa bertanya lagi ke listing yang sama dalam jangka ini
+	MaxInquiryMessageURLs = 2              // Pesan dengan link lebih banyak dari ini dianggap spam
+)
+
+var (
+	phoneCleaner = regexp.MustCompile(`[\s\-().]`)
+	phonePattern = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+	urlPattern   = regexp.MustCompile(`(?i)https?://|www\.`)
+)
+
+type InquiryHandler struct {
+	repo *database.InquiryRepository
+}
+
+// NewInquiryHandler membuat instance baru InquiryHandler
+func NewInquiryHandler(repo *database.InquiryRepository) *InquiryHandler {
+	return &InquiryHandler{repo: repo}
+}
+
+// InquiryInput form pertanyaan calon pembeli. Website adalah honeypot: field tersembunyi di
+// form yang hanya diisi bot.
+type InquiryInput struct {
+	Name             string `json:"name" binding:"required,min=2,max=100"`
+	Phone            string `json:"phone" binding:"required,max=30"`
+	Email            string `json:"email" binding:"omitempty,email,max=255"`
+	Message          string `json:"message" binding:"required,min=10,max=2000"`
+	PreferredContact string `json:"preferred_contact" binding:"omitempty,oneof=phone whatsapp email"`
+	Website          string `json:"website"`
+}
+
+// UpdateInquiryRequest perubahan lead oleh pemilik listing, field yang tidak dikirim tidak diubah
+type UpdateInquiryRequest struct {
+	Status *string `json:"status" binding:"omitempty,oneof=new contacted qualified closed"`
+	Notes  *string `json:"notes" binding:"omitempty,max=2000"`
+	Read   *bool   `json:"read"`
+}
+
+// CreateInquiry menerima pertanyaan calon pembeli tentang listing (PUBLIC, tidak perlu login)
+func (h *InquiryHandler) CreateInquiry(c *gin.Context) {
+	propertyID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return
+	}
+
+	var input InquiryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validasi gagal",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Bot yang mengisi honeypot dapat response sukses palsu supaya tidak mencoba cara lain
+	if input.Website != "" {
+		c.JSON(http.StatusCreated, gin.H{"message": "Pertanyaan terkirim ke pemilik listing"})
+		return
+	}
+
+	inquiry := models.Inquiry{
+		PropertyID:       uint(propertyID),
+		Name:             strings.TrimSpace(input.Name),
+		Email:            strings.TrimSpace(input.Email),
+		Message:          strings.TrimSpace(input.Message),
+		PreferredContact: input.PreferredContact,
+		IPAddress:        c.ClientIP(),
+		UserAgent:        truncate(c.Request.UserAgent(), 255),
+	}
+	if inquiry.PreferredContact == "" {
+		inquiry.PreferredContact = models.ContactWhatsApp
+	}
+	phone, ok := normalizePhone(input.Phone)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": "Nomor telepon tidak valid"})
+		return
+	}
+	inquiry.Phone = phone
+	if inquiry.PreferredContact == models.ContactEmail && inquiry.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": "email wajib diisi kalau ingin dihubungi lewat email"})
+		return
+	}
+	if len(urlPattern.FindAllString(inquiry.Message, -1)) > MaxInquiryMessageURLs {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pesan terdeteksi sebagai spam", "code": "SPAM_DETECTED"})
+		return
+	}
+
+	now := time.Now()
+	count, err := h.repo.CountRecentByIP(inquiry.IPAddress, now.Add(-InquiryRateWindow))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+	if count >= MaxInquiriesPerIP {
+		c.Header("Retry-After", strconv.Itoa(int(InquiryRateWindow.Seconds())))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Terlalu banyak pertanyaan terkirim, coba lagi nanti", "code": "RATE_LIMITED"})
+		return
+	}
+	repeated, err := h.repo.HasRecentInquiry(inquiry.PropertyID, inquiry.Phone, now.Add(-InquiryRepeatWindow))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+	if repeated {
+		c.JSON(http.StatusConflict, gin.H{"error": "Kamu sudah mengirim pertanyaan untuk listing ini, tunggu pemilik menghubungi", "code": "DUPLICATE_INQUIRY"})
+		return
+	}
+
+	if err := h.repo.CreateInquiry(&inquiry); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal menyimpan data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Pertanyaan terkirim ke pemilik listing"})
+}
+
+// GetInbox mengambil inquiry untuk listing milik user yang login.
+// Filter: ?status=, ?unread=true, ?property_id=, pagination ?page=&limit=
+func (h *InquiryHandler) GetInbox(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	ownerID, _ := userID.(uint)
+
+	filter := database.InquiryFilter{
+		Status:     c.Query("status"),
+		UnreadOnly: utils.ParseBool(c.Query("unread")),
+	}
+	switch filter.Status {
+	case "", models.InquiryStatusNew, models.InquiryStatusContacted, models.InquiryStatusQualified, models.InquiryStatusClosed:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validasi gagal",
+			"details": "status harus new, contacted, qualified atau closed",
+		})
+		return
+	}
+	if raw := c.Query("property_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property_id"})
+			return
+		}
+		filter.PropertyID = uint(id)
+	}
+	page, limit := utils.ParsePagination(c)
+
+	inquiries, total, err := h.repo.GetOwnerInquiries(ownerID, filter, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+	unread, err := h.repo.CountUnread(ownerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": inquiries,
+		"pagination": utils.PaginationMetadata{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: utils.CalculateTotalPages(total, limit),
+		},
+		"unread": unread,
+	})
+}
+
+// GetInquiry mengambil satu inquiry dan menandainya sudah dibaca
+func (h *InquiryHandler) GetInquiry(c *gin.Context) {
+	inquiry, ok := h.ownedInquiry(c)
+	if !ok {
+		return
+	}
+	if err := h.repo.MarkRead(inquiry); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan data", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": inquiry})
+}
+
+// UpdateInquiry mengubah status, catatan atau status baca inquiry
+func (h *InquiryHandler) UpdateInquiry(c *gin.Context) {
+	inquiry, ok := h.ownedInquiry(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateInquiryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validasi gagal",
+			"details": err.Error(),
+		})
+		return
+	}
+	if req.Status != nil {
+		inquiry.Status = *req.Status
+	}
+	if req.Notes != nil {
+		inquiry.Notes = strings.TrimSpace(*req.Notes)
+	}
+	if req.Read != nil {
+		switch {
+		case !*req.Read:
+			inquiry.ReadAt = nil
+		case inquiry.ReadAt == nil:
+			now := time.Now()
+			inquiry.ReadAt = &now
+		}
+	}
+
+	if err := h.repo.UpdateInquiry(inquiry); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal menyimpan data",
+			"details": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": inquiry})
+}
+
+// ownedInquiry mengambil inquiry dari param :id dan memastikan masuk ke inbox user yang login
+func (h *InquiryHandler) ownedInquiry(c *gin.Context) (*models.Inquiry, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return nil, false
+	}
+
+	inquiry, err := h.repo.GetInquiryByID(uint(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		}
+		return nil, false
+	}
+
+	// Inquiry milik orang lain dianggap tidak ada
+	userID, _ := c.Get("userID")
+	if uid, ok := userID.(uint); !ok || inquiry.OwnerID != uid {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		return nil, false
+	}
+	return inquiry, true
+}
+
+// normalizePhone menormalisasi nomor telepon ke format internasional, nomor lokal Indonesia
+// (08xx atau 62xx) diubah ke +62. false kalau formatnya tidak valid.
+func normalizePhone(raw string) (string, bool) {
+	phone := phoneCleaner.ReplaceAllString(strings.TrimSpace(raw), "")
+	switch {
+	case strings.HasPrefix(phone, "+"):
+	case strings.HasPrefix(phone, "0"):
+		phone = "+62" + phone[1:]
+	case strings.HasPrefix(phone, "62"):
+		phone = "+" + phone
+	default:
+		return "", false
+	}
+	return phone, phonePattern.MatchString(phone)
+}
+
+// truncate memotong string ke maksimal n byte
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+	"project-zero/pkg/database"
+	"project-zero/pkg/imaging"
+	"project-zero/pkg/media"
+	"project-zero/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxDuplicateDistance batas parameter distance laporan duplikat, di atas ini hasilnya sudah tidak relevan
+const MaxDuplicateDistance = 16
+
+type MediaHandler struct {
+	gc     *media.Collector
+	assets *database.MediaAssetRepository
+}
+
+// NewMediaHandler membuat instance baru MediaHandler
+func NewMediaHandler(gc *media.Collector, assets *database.MediaAssetRepository) *MediaHandler {
+	return &MediaHandler{gc: gc, assets: assets}
+}
+
+// GetOrphanReport laporan dry-run asset yang akan dihapus GC, tanpa menghapus apa pun (admin only)
+func (h *MediaHandler) GetOrphanReport(c *gin.Context) {
+	report, err := h.gc.Run(c.Request.Context(), true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal mengambil data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// RunGarbageCollection menjalankan GC media sekarang juga (admin only)
+func (h *MediaHandler) RunGarbageCollection(c *gin.Context) {
+	report, err := h.gc.Run(c.Request.Context(), false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal menjalankan GC media",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// GetSharedImages laporan foto identik/mirip yang dipakai di listing milik user berbeda (admin only).
+// ?distance= jarak Hamming maksimal perceptual hash, 0 hanya foto identik. Pagination ?page=&limit=
+func (h *MediaHandler) GetSharedImages(c *gin.Context) {
+	distance := imaging.NearDuplicateDistance
+	if raw := c.Query("distance"); raw != "" {
+		d, err := strconv.Atoi(raw)
+		if err != nil || d < 0 || d > MaxDuplicateDistance {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validasi gagal",
+				"details": "distance harus angka 0 sampai " + strconv.Itoa(MaxDuplicateDistance),
+			})
+			return
+		}
+		distance = d
+	}
+
+	page, limit := utils.ParsePagination(c)
+	groups, total, err := h.assets.FindSharedAcrossOwners(distance, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal mengambil data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":     groups,
+		"distance": distance,
+		"pagination": utils.PaginationMetadata{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: utils.CalculateTotalPages(total, limit),
+		},
+	})
+}
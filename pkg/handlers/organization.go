@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"project-zero/internal/models"
+	"project-zero/pkg/database"
+	"project-zero/pkg/imaging"
+	"project-zero/pkg/storage"
+	"strconv"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// WatermarkFolder folder storage untuk logo watermark organisasi
+const WatermarkFolder = "watermarks"
+
+// MaxWatermarkLogoSize batas ukuran file logo watermark
+const MaxWatermarkLogoSize = 2 * 1024 * 1024 // 2MB
+
+type OrganizationHandler struct {
+	repo  *database.OrganizationRepository
+	store storage.Storage
+}
+
+// NewOrganizationHandler membuat instance baru OrganizationHandler
+func NewOrganizationHandler(repo *database.OrganizationRepository, store storage.Storage) *OrganizationHandler {
+	return &OrganizationHandler{repo: repo, store: store}
+}
+
+type SetUserOrganizationRequest struct {
+	OrganizationID *uint `json:"organization_id"` // null untuk mengeluarkan user dari organisasi
+}
+
+// GetAllOrganizations mengambil semua organisasi beserta pengaturan watermark-nya (admin only)
+func (h *OrganizationHandler) GetAllOrganizations(c *gin.Context) {
+	organizations, err := h.repo.GetAllOrganizations()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal mengambil data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": organizations})
+}
+
+// CreateOrganization membuat organisasi baru (admin only)
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	var input models.Organization
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validasi gagal",
+			"details": err.Error(),
+		})
+		return
+	}
+	input.ID = 0
+	input.Watermark.LogoURL = ""
+	normalizeWatermark(&input.Watermark)
+
+	if err := h.repo.CreateOrganization(&input); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal menyimpan data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": input})
+}
+
+// UpdateWatermark mengganti pengaturan watermark organisasi (admin only).
+// Logo tidak diubah di sini, gunakan UploadWatermarkLogo.
+func (h *OrganizationHandler) UpdateWatermark(c *gin.Context) {
+	organization, ok := h.findOrganization(c)
+	if !ok {
+		return
+	}
+
+	var input models.WatermarkSettings
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validasi gagal",
+			"details": err.Error(),
+		})
+		return
+	}
+	input.LogoURL = organization.Watermark.LogoURL
+	normalizeWatermark(&input)
+	if input.Enabled && input.Text == "" && input.LogoURL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validasi gagal",
+			"details": "Isi text atau upload logo sebelum mengaktifkan watermark",
+		})
+		return
+	}
+
+	organization.Watermark = input
+	if err := h.repo.UpdateWatermark(organization); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal menyimpan data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": organization})
+}
+
+// UploadWatermarkLogo mengganti logo watermark organisasi (admin only).
+// Logo disimpan apa adanya tanpa diproses, PNG transparan disarankan.
+func (h *OrganizationHandler) UploadWatermarkLogo(c *gin.Context) {
+	organization, ok := h.findOrganization(c)
+	if !ok {
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal ambil file"})
+		return
+	}
+	if file.Size > MaxWatermarkLogoSize {
+		respondValidationError(c, &ValidationError{Code: "FILE_TOO_LARGE", Message: "Ukuran logo terlalu besar, maksimal 2MB"})
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal membuka file"})
+		return
+	}
+	defer src.Close()
+	data, err := io.ReadAll(src)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal membaca file"})
+		return
+	}
+	if err := validateImageContent(data); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	key := storage.NewKey(WatermarkFolder, file.Filename)
+	url, err := h.store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), mimetype.Detect(data).String())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal upload ke storage", "details": err.Error()})
+		return
+	}
+
+	oldLogo := organization.Watermark.LogoURL
+	organization.Watermark.LogoURL = url
+	if err := h.repo.UpdateWatermark(organization); err != nil {
+		h.store.Delete(ctx, key)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal menyimpan data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if oldKey, ok := h.store.KeyFromURL(oldLogo); ok {
+		if err := h.store.Delete(ctx, oldKey); err != nil {
+			fmt.Printf("⚠️  Gagal hapus logo lama %s: %v\n", oldKey, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": organization})
+}
+
+// SetUserOrganization memasukkan user ke organisasi atau mengeluarkannya (admin only)
+func (h *OrganizationHandler) SetUserOrganization(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var req SetUserOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validasi gagal",
+			"details": err.Error(),
+		})
+		return
+	}
+	if req.OrganizationID != nil {
+		if _, err := h.repo.GetOrganizationByID(*req.OrganizationID); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": "organization_id tidak ditemukan"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+			}
+			return
+		}
+	}
+
+	if err := h.repo.SetUserOrganization(uint(userID), req.OrganizationID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal menyimpan data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"user_id": userID, "organization_id": req.OrganizationID}})
+}
+
+// findOrganization mengambil organisasi dari param :id, response error langsung dikirim kalau gagal
+func (h *OrganizationHandler) findOrganization(c *gin.Context) (*models.Organization, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return nil, false
+	}
+
+	organization, err := h.repo.GetOrganizationByID(uint(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		}
+		return nil, false
+	}
+	return organization, true
+}
+
+// normalizeWatermark mengisi default posisi, opacity dan ukuran watermark
+func normalizeWatermark(w *models.WatermarkSettings) {
+	if w.Position == "" {
+		w.Position = imaging.PositionBottomRight
+	}
+	if w.Opacity == 0 {
+		w.Opacity = imaging.DefaultWatermarkOpacity
+	}
+	if w.Scale == 0 {
+		w.Scale = imaging.DefaultWatermarkScale
+	}
+}
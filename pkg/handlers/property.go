@@ -1,11 +1,12 @@
 package handlers
 
 import (
-	"mime/multipart"
+	"fmt"
 	"net/http"
-	"path/filepath"
 	"project-zero/internal/models"
 	"project-zero/pkg/database"
+	"project-zero/pkg/rupiah"
+	"project-zero/pkg/storage"
 	"project-zero/pkg/utils"
 	"strconv"
 
@@ -13,27 +14,17 @@ import (
 	"gorm.io/gorm"
 )
 
-// Constants untuk image validation
-const (
-	MaxFileSize   = 100 * 1024 * 1024 // 100MB
-	MaxFileSizeMB = 100
-)
-
-var AllowedImageTypes = map[string]bool{
-	".jpg":  true,
-	".jpeg": true,
-	".png":  true,
-	".gif":  true,
-	".webp": true,
-}
-
 type PropertyHandler struct {
-	repo *database.PropertyRepository
+	repo      *database.PropertyRepository
+	rates     *database.ExchangeRateRepository
+	favorites *database.FavoriteRepository
+	store     storage.Storage // Foto listing untuk brosur
+	private   storage.Storage // Dokumen legal listing
 }
 
 // NewPropertyHandler membuat instance baru PropertyHandler
-func NewPropertyHandler(repo *database.PropertyRepository) *PropertyHandler {
-	return &PropertyHandler{repo: repo}
+func NewPropertyHandler(repo *database.PropertyRepository, rates *database.ExchangeRateRepository, favorites *database.FavoriteRepository, store, private storage.Storage) *PropertyHandler {
+	return &PropertyHandler{repo: repo, rates: rates, favorites: favorites, store: store, private: private}
 }
 
 // CreateProperty membuat property baru
@@ -54,10 +45,27 @@ func (h *PropertyHandler) CreateProperty(c *gin.Context) {
 		return
 	}
 
+	// Validasi syarat sewa terhadap ListingType
+	if err := input.ValidateRentalTerms(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validasi gagal",
+			"details": err.Error(),
+		})
+		return
+	}
+	input.NormalizeRentalPrice()
+	if input.Currency == "" {
+		input.Currency = utils.DefaultCurrency
+	}
+
 	// Set UserID dari token JWT
 	input.UserID = userID.(uint)
 
 	err := h.repo.CreateProperty(&input)
+	if err == database.ErrUnknownAmenity {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Gagal menyimpan data",
@@ -94,6 +102,22 @@ func (h *PropertyHandler) GetAllProperties(c *gin.Context) {
 		return
 	}
 
+	// Harga dalam mata uang lain (optional, ?currencies=USD,SGD)
+	if err := applyPriceDisplay(h.rates, properties, params.Currencies, params.PriceWords, params.UserID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal mengambil kurs",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err := applyFavorites(h.favorites, properties, params.UserID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal mengambil data",
+			"details": err.Error(),
+		})
+		return
+	}
+
 	// Build pagination metadata
 	pagination := utils.PaginationMetadata{
 		Page:       params.Page,
@@ -114,6 +138,19 @@ func (h *PropertyHandler) GetAllProperties(c *gin.Context) {
 		response.Filters = filters
 	}
 
+	// Facet counts (optional, ?facets=true)
+	if params.WithFacets {
+		facets, err := h.repo.GetFacets(params)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Gagal menghitung facet",
+				"details": err.Error(),
+			})
+			return
+		}
+		response.Facets = facets
+	}
+
 	c.JSON(http.StatusOK, response)
 }
 
@@ -138,7 +175,129 @@ func (h *PropertyHandler) GetPropertyByID(c *gin.Context) {
 		}
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"data": property})
+
+	userID, _ := c.Get("userID")
+	viewerID, _ := userID.(uint)
+	properties := []models.Property{*property}
+	currencies := utils.ParseCurrencies(c.Query("currencies"))
+	if err := applyPriceDisplay(h.rates, properties, currencies, utils.ParseBool(c.Query("terbilang")), viewerID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal mengambil kurs",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err := applyFavorites(h.favorites, properties, viewerID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal mengambil data",
+			"details": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": properties[0]})
+}
+
+// applyPriceDisplay mengisi price_formatted, price_words dan converted_prices, serta menyembunyikan
+// harga "hubungi kami" untuk viewer yang bukan pemilik listing. Konversi hanya memakai kurs di database.
+func applyPriceDisplay(rateRepo *database.ExchangeRateRepository, properties []models.Property, currencies []string, withWords bool, viewerID uint) error {
+	var rates map[string]float64
+	if len(currencies) > 0 {
+		var err error
+		if rates, err = rateRepo.GetRatesMap(); err != nil {
+			return err
+		}
+	}
+
+	for i := range properties {
+		p := &properties[i]
+		if p.PriceHidden && p.UserID != viewerID {
+			p.MaskHiddenPrice()
+			continue
+		}
+
+		if p.Currency == utils.DefaultCurrency {
+			p.PriceFormatted = rupiah.Format(p.Price)
+			if withWords {
+				p.PriceWords = rupiah.Terbilang(p.Price)
+			}
+		} else {
+			p.PriceFormatted = p.Currency + " " + rupiah.FormatNumber(p.Price)
+		}
+
+		for _, currency := range currencies {
+			if currency == p.Currency {
+				continue
+			}
+			if amount, ok := utils.ConvertPrice(p.Price, p.Currency, currency, rates); ok {
+				if p.ConvertedPrices == nil {
+					p.ConvertedPrices = make(map[string]int64)
+				}
+				p.ConvertedPrices[currency] = amount
+			}
+		}
+	}
+	return nil
+}
+
+// applyFavorites mengisi is_favorite untuk viewer, dan favorite_count untuk listing milik viewer
+func applyFavorites(favorites *database.FavoriteRepository, properties []models.Property, viewerID uint) error {
+	if viewerID == 0 || len(properties) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(properties))
+	var ownedIDs []uint
+	for _, p := range properties {
+		ids = append(ids, p.ID)
+		if p.UserID == viewerID {
+			ownedIDs = append(ownedIDs, p.ID)
+		}
+	}
+
+	saved, err := favorites.FavoritePropertyIDs(viewerID, ids)
+	if err != nil {
+		return err
+	}
+	counts, err := favorites.CountFavorites(ownedIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range properties {
+		p := &properties[i]
+		p.IsFavorite = saved[p.ID]
+		if p.UserID == viewerID {
+			count := counts[p.ID]
+			p.FavoriteCount = &count
+		}
+	}
+	return nil
+}
+
+// GetPriceHistory mengambil riwayat perubahan harga property
+func (h *PropertyHandler) GetPriceHistory(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	viewerID, _ := userID.(uint)
+	history, err := h.repo.GetPriceHistory(uint(id), viewerID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Gagal mengambil data",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": history})
 }
 
 // UpdateProperty mengupdate property yang sudah ada
@@ -159,10 +318,25 @@ func (h *PropertyHandler) UpdateProperty(c *gin.Context) {
 		return
 	}
 
+	// Validasi syarat sewa terhadap ListingType
+	if err := input.ValidateRentalTerms(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validasi gagal",
+			"details": err.Error(),
+		})
+		return
+	}
+	input.NormalizeRentalPrice()
+	if input.Currency == "" {
+		input.Currency = utils.DefaultCurrency
+	}
+
 	property, err := h.repo.UpdateProperty(uint(id), &input)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		} else if err == database.ErrUnknownAmenity {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": err.Error()})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error":   "Gagal mengupdate data",
@@ -184,76 +358,24 @@ func (h *PropertyHandler) DeleteProperty(c *gin.Context) {
 		return
 	}
 
-	err = h.repo.DeleteProperty(uint(id))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Gagal menghapus data",
-			"details": err.Error(),
-		})
-		return
-	}
-	c.JSON(http.StatusOK, gin.H{"message": "Listing berhasil dihapus"})
-}
-
-// UploadFile menghandle upload file dengan validasi dan upload ke Cloudinary
-func UploadFile(c *gin.Context) {
-	file, err := c.FormFile("file")
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal ambil file"})
-		return
-	}
-
-	// Validate file
-	if err := validateImageFile(file); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	documentKeys, err := h.repo.DeleteProperty(uint(id))
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
 		return
 	}
-
-	// Upload ke Cloudinary
-	cloudinaryURL, err := utils.UploadToCloudinary(file)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Gagal upload ke cloud",
+			"error":   "Gagal menghapus data",
 			"details": err.Error(),
 		})
 		return
 	}
 
-	// Return Cloudinary URL untuk database
-	c.JSON(http.StatusOK, gin.H{
-		"photo_path": cloudinaryURL,
-		"message":    "File berhasil diupload ke Cloudinary",
-	})
-}
-
-// validateImageFile memvalidasi file image berdasarkan tipe dan ukuran
-func validateImageFile(file *multipart.FileHeader) error {
-	// Validasi ukuran file
-	if file.Size > MaxFileSize {
-		return &ValidationError{
-			Code:    "FILE_TOO_LARGE",
-			Message: "Ukuran file terlalu besar, maksimal " + strconv.Itoa(MaxFileSizeMB) + "MB",
-		}
-	}
-
-	// Validasi tipe file (extension)
-	ext := filepath.Ext(file.Filename)
-	if !AllowedImageTypes[ext] {
-		return &ValidationError{
-			Code:    "INVALID_FILE_TYPE",
-			Message: "Tipe file tidak didukung, gunakan: jpg, jpeg, png, gif, webp",
+	// Dokumen legal tidak dibiarkan tertinggal di storage private
+	for _, key := range documentKeys {
+		if err := h.private.Delete(c.Request.Context(), key); err != nil {
+			fmt.Printf("⚠️  Gagal hapus dokumen %s dari storage private: %v\n", key, err)
 		}
 	}
-
-	return nil
-}
-
-// ValidationError custom error untuk validasi
-type ValidationError struct {
-	Code    string
-	Message string
-}
-
-func (e *ValidationError) Error() string {
-	return e.Message
+	c.JSON(http.StatusOK, gin.H{"message": "Listing berhasil dihapus"})
 }
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"project-zero/pkg/brochure"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetBrochure membuat brosur PDF listing (cover, galeri, spesifikasi, kontak agen, QR code)
+func (h *PropertyHandler) GetBrochure(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return
+	}
+
+	property, err := h.repo.GetPropertyByID(uint(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Gagal mengambil data",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	photos, err := h.repo.GetPropertyPhotos(property.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil foto", "details": err.Error()})
+		return
+	}
+
+	agent, err := h.repo.GetOwner(property.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data agen", "details": err.Error()})
+		return
+	}
+
+	brand := os.Getenv("BROCHURE_BRAND_NAME")
+	if brand == "" {
+		brand = "Project Zero"
+	}
+
+	// Render dulu ke buffer supaya error masih bisa dikirim sebagai JSON
+	var buf bytes.Buffer
+	err = brochure.Render(c.Request.Context(), &buf, brochure.Data{
+		Property:   *property,
+		Photos:     photos,
+		Agent:      *agent,
+		ListingURL: publicListingURL(property.ID),
+		BrandName:  brand,
+		Store:      h.store,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat brosur", "details": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="brosur-properti-%d.pdf"`, property.ID))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+// publicListingURL link listing publik untuk QR code brosur
+func publicListingURL(id uint) string {
+	return fmt.Sprintf("%s/?property=%d", publicBaseURL(), id)
+}
+
+// publicBaseURL alamat publik aplikasi dari PUBLIC_BASE_URL, fallback ke localhost untuk development
+func publicBaseURL() string {
+	base := os.Getenv("PUBLIC_BASE_URL")
+	if base == "" {
+		port := os.Getenv("PORT")
+		if port == "" {
+			port = "8080"
+		}
+		base = "http://localhost:" + port
+	}
+	return strings.TrimRight(base, "/")
+}
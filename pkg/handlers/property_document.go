@@ -0,0 +1,361 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"project-zero/internal/models"
+	"project-zero/pkg/database"
+	"project-zero/pkg/storage"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Batas dokumen legal
+const (
+	MaxDocumentSize   = 20 * 1024 * 1024 // 20MB
+	MaxDocumentSizeMB = 20
+
+	DocumentFolder     = "documents"
+	DocumentLinkExpiry = 10 * time.Minute // Masa berlaku link download dokumen
+)
+
+// AllowedDocumentTypes tipe MIME dokumen yang diterima: PDF dan hasil scan
+var AllowedDocumentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/tiff":      true,
+}
+
+type PropertyDocumentHandler struct {
+	documents *database.PropertyDocumentRepository
+	photos    *database.PropertyPhotoRepository
+	private   storage.Storage
+}
+
+// NewPropertyDocumentHandler membuat instance baru PropertyDocumentHandler
+func NewPropertyDocumentHandler(documents *database.PropertyDocumentRepository, photos *database.PropertyPhotoRepository, private storage.Storage) *PropertyDocumentHandler {
+	return &PropertyDocumentHandler{documents: documents, photos: photos, private: private}
+}
+
+// DocumentInput data dokumen dari form upload maupun JSON update. Tanggal format YYYY-MM-DD.
+type DocumentInput struct {
+	Type      string `json:"type" form:"type" binding:"required,oneof=certificate imb pbg pbb ajb other"`
+	Title     string `json:"title" form:"title" binding:"max=150"`
+	Number    string `json:"number" form:"number" binding:"max=100"`
+	IssuedAt  string `json:"issued_at" form:"issued_at"`
+	ExpiresAt string `json:"expires_at" form:"expires_at"`
+	Notes     string `json:"notes" form:"notes" binding:"max=1000"`
+}
+
+// apply menyalin input ke dokumen, error kalau format tanggal salah
+func (in *DocumentInput) apply(document *models.PropertyDocument) error {
+	issuedAt, err := parseOptionalDate("issued_at", in.IssuedAt)
+	if err != nil {
+		return err
+	}
+	expiresAt, err := parseOptionalDate("expires_at", in.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	if issuedAt != nil && expiresAt != nil && expiresAt.Before(*issuedAt) {
+		return fmt.Errorf("expires_at tidak boleh sebelum issued_at")
+	}
+
+	document.Type = in.Type
+	document.Title = strings.TrimSpace(in.Title)
+	document.Number = strings.TrimSpace(in.Number)
+	document.IssuedAt = issuedAt
+	document.ExpiresAt = expiresAt
+	document.Notes = strings.TrimSpace(in.Notes)
+	return nil
+}
+
+// UploadPropertyDocument mengupload dokumen legal ke storage private (multipart: file, type, title,
+// number, issued_at, expires_at, notes). Hanya pemilik listing.
+func (h *PropertyDocumentHandler) UploadPropertyDocument(c *gin.Context) {
+	propertyID, ok := ownedPropertyID(c, h.photos, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var input DocumentInput
+	if err := c.ShouldBind(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": err.Error()})
+		return
+	}
+	var document models.PropertyDocument
+	if err := input.apply(&document); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": err.Error()})
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal ambil file"})
+		return
+	}
+	if file.Size > MaxDocumentSize {
+		respondValidationError(c, &ValidationError{
+			Code:    "FILE_TOO_LARGE",
+			Message: "Ukuran dokumen terlalu besar, maksimal " + strconv.Itoa(MaxDocumentSizeMB) + "MB",
+		})
+		return
+	}
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal membuka file"})
+		return
+	}
+	defer src.Close()
+	data, err := io.ReadAll(src)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal membaca file"})
+		return
+	}
+
+	// Tipe dari magic bytes, extension file ikut disamakan dengan isi sebenarnya
+	mime := mimetype.Detect(data)
+	if !AllowedDocumentTypes[mime.String()] {
+		respondValidationError(c, &ValidationError{
+			Code:    "INVALID_FILE_TYPE",
+			Message: "Tipe dokumen tidak didukung (" + mime.String() + "), gunakan: pdf, jpg, png, tiff",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	folder := fmt.Sprintf("%s/%d", DocumentFolder, propertyID)
+	key := storage.NewKey(folder, strings.TrimSuffix(file.Filename, filepath.Ext(file.Filename))+mime.Extension())
+	if _, err := h.private.Put(ctx, key, bytes.NewReader(data), int64(len(data)), mime.String()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal upload ke storage", "details": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	document.PropertyID = propertyID
+	document.UserID = userID.(uint)
+	document.OriginalName = file.Filename
+	document.ContentType = mime.String()
+	document.Size = int64(len(data))
+	document.StorageKey = key
+	if err := h.documents.CreateDocument(&document); err != nil {
+		if err := h.private.Delete(ctx, key); err != nil {
+			fmt.Printf("⚠️  Gagal hapus file %s dari storage private: %v\n", key, err)
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan data", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": document})
+}
+
+// GetPropertyDocuments daftar dokumen listing untuk pemiliknya
+func (h *PropertyDocumentHandler) GetPropertyDocuments(c *gin.Context) {
+	propertyID, ok := ownedPropertyID(c, h.photos, c.Param("id"))
+	if !ok {
+		return
+	}
+	h.respondDocuments(c, propertyID)
+}
+
+// AdminGetPropertyDocuments daftar dokumen listing mana pun (admin only)
+func (h *PropertyDocumentHandler) AdminGetPropertyDocuments(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return
+	}
+	h.respondDocuments(c, uint(id))
+}
+
+// UpdatePropertyDocument mengubah jenis, nomor, tanggal dan catatan dokumen. File tidak bisa diganti,
+// upload dokumen baru lalu hapus yang lama.
+func (h *PropertyDocumentHandler) UpdatePropertyDocument(c *gin.Context) {
+	document, ok := h.ownedDocument(c)
+	if !ok {
+		return
+	}
+
+	var input DocumentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": err.Error()})
+		return
+	}
+	if err := input.apply(document); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": err.Error()})
+		return
+	}
+
+	if err := h.documents.UpdateDocument(document); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan data", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": document})
+}
+
+// DeletePropertyDocument menghapus dokumen beserta file-nya di storage private
+func (h *PropertyDocumentHandler) DeletePropertyDocument(c *gin.Context) {
+	document, ok := h.ownedDocument(c)
+	if !ok {
+		return
+	}
+
+	if err := h.documents.DeleteDocument(document); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus data", "details": err.Error()})
+		return
+	}
+	if err := h.private.Delete(c.Request.Context(), document.StorageKey); err != nil {
+		fmt.Printf("⚠️  Gagal hapus dokumen %s dari storage private: %v\n", document.StorageKey, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Dokumen berhasil dihapus"})
+}
+
+// GetDocumentDownloadLink membuat link download sementara untuk pemilik listing
+func (h *PropertyDocumentHandler) GetDocumentDownloadLink(c *gin.Context) {
+	document, ok := h.ownedDocument(c)
+	if !ok {
+		return
+	}
+	h.respondDownloadLink(c, document)
+}
+
+// AdminGetDocumentDownloadLink membuat link download sementara untuk admin (reviewer)
+func (h *PropertyDocumentHandler) AdminGetDocumentDownloadLink(c *gin.Context) {
+	document, ok := h.findDocument(c)
+	if !ok {
+		return
+	}
+	h.respondDownloadLink(c, document)
+}
+
+// GetExpiringDocuments dokumen listing milik user yang sudah/akan kadaluarsa dalam ?days= hari (default 90)
+func (h *PropertyDocumentHandler) GetExpiringDocuments(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	h.respondExpiring(c, userID.(uint))
+}
+
+// AdminGetExpiringDocuments dokumen semua listing yang sudah/akan kadaluarsa (admin only)
+func (h *PropertyDocumentHandler) AdminGetExpiringDocuments(c *gin.Context) {
+	h.respondExpiring(c, 0)
+}
+
+func (h *PropertyDocumentHandler) respondDocuments(c *gin.Context, propertyID uint) {
+	documents, err := h.documents.GetPropertyDocuments(propertyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": documents})
+}
+
+func (h *PropertyDocumentHandler) respondDownloadLink(c *gin.Context, document *models.PropertyDocument) {
+	url, err := h.private.SignedURL(c.Request.Context(), document.StorageKey, DocumentLinkExpiry)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat link download", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"url":        url,
+		"expires_at": time.Now().Add(DocumentLinkExpiry),
+	}})
+}
+
+func (h *PropertyDocumentHandler) respondExpiring(c *gin.Context, ownerID uint) {
+	days := 90
+	if raw := c.Query("days"); raw != "" {
+		d, err := strconv.Atoi(raw)
+		if err != nil || d < 0 || d > 3650 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": "days harus angka 0 sampai 3650"})
+			return
+		}
+		days = d
+	}
+
+	documents, err := h.documents.GetExpiringDocuments(ownerID, time.Now().AddDate(0, 0, days))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": documents, "days": days})
+}
+
+// ownedPropertyID memastikan listing ada dan milik user yang login
+func ownedPropertyID(c *gin.Context, photos *database.PropertyPhotoRepository, idStr string) (uint, bool) {
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return 0, false
+	}
+
+	ownerID, err := photos.GetPropertyOwnerID(uint(id))
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		return 0, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return 0, false
+	}
+
+	userID, _ := c.Get("userID")
+	if uid, ok := userID.(uint); !ok || ownerID != uid {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Bukan listing milik kamu"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// findDocument mengambil dokumen dari param :id
+func (h *PropertyDocumentHandler) findDocument(c *gin.Context) (*models.PropertyDocument, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return nil, false
+	}
+
+	document, err := h.documents.GetDocumentByID(uint(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		}
+		return nil, false
+	}
+	return document, true
+}
+
+// ownedDocument mengambil dokumen dan memastikan listing-nya milik user yang login
+func (h *PropertyDocumentHandler) ownedDocument(c *gin.Context) (*models.PropertyDocument, bool) {
+	document, ok := h.findDocument(c)
+	if !ok {
+		return nil, false
+	}
+	if _, ok := ownedPropertyID(c, h.photos, strconv.FormatUint(uint64(document.PropertyID), 10)); !ok {
+		return nil, false
+	}
+	return document, true
+}
+
+// parseOptionalDate membaca tanggal YYYY-MM-DD, string kosong berarti tidak diisi
+func parseOptionalDate(field, value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("%s harus format YYYY-MM-DD", field)
+	}
+	return &t, nil
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"project-zero/internal/models"
+	"project-zero/pkg/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+)
+
+// exportColumns urutan kolom untuk export CSV/XLSX
+var exportColumns = []string{
+	"id", "title", "description", "listing_type", "price", "currency", "price_negotiable", "price_hidden",
+	"price_period", "min_lease_months", "deposit", "monthly_price", "land_size", "building_size",
+	"bedrooms", "bathrooms", "floors", "certificate", "electricity", "water_source", "address",
+	"amenities", "photo_path", "photo_urls", "created_at", "updated_at",
+}
+
+// exportRecord satu baris JSON Lines: semua field property plus photo_urls
+type exportRecord struct {
+	models.Property
+	PhotoURLs []string `json:"photo_urls"`
+}
+
+// ExportProperties export listing milik user ke CSV, XLSX atau JSON Lines
+// dengan filter yang sama seperti GetAllProperties (tanpa pagination)
+func (h *PropertyHandler) ExportProperties(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	params := utils.ParseQueryParams(c)
+	params.UserID = userID.(uint)
+
+	format := c.DefaultQuery("format", "csv")
+	filename := "properties-" + time.Now().Format("20060102-150405") + "." + format
+
+	var err error
+	switch format {
+	case "csv":
+		setDownloadHeaders(c, "text/csv; charset=utf-8", filename)
+		err = h.exportCSV(c, params)
+	case "jsonl":
+		setDownloadHeaders(c, "application/x-ndjson", filename)
+		err = h.exportJSONL(c, params)
+	case "xlsx":
+		setDownloadHeaders(c, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", filename)
+		err = h.exportXLSX(c, params)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format tidak didukung, gunakan: csv, xlsx, jsonl"})
+		return
+	}
+
+	// Response sudah mulai dikirim, jadi error hanya bisa dicatat
+	if err != nil {
+		fmt.Printf("❌ Gagal export properties (user %d, format %s): %v\n", params.UserID, format, err)
+		c.Abort()
+	}
+}
+
+func (h *PropertyHandler) exportCSV(c *gin.Context, params utils.QueryParams) error {
+	w := csv.NewWriter(c.Writer)
+	if err := w.Write(exportColumns); err != nil {
+		return err
+	}
+
+	err := h.repo.ExportProperties(params, func(batch []models.Property, photos map[uint][]string) error {
+		for i := range batch {
+			if err := w.Write(exportRow(&batch[i], photos[batch[i].ID])); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		c.Writer.Flush()
+		return w.Error()
+	})
+	w.Flush()
+	return err
+}
+
+func (h *PropertyHandler) exportJSONL(c *gin.Context, params utils.QueryParams) error {
+	enc := json.NewEncoder(c.Writer)
+	return h.repo.ExportProperties(params, func(batch []models.Property, photos map[uint][]string) error {
+		for i := range batch {
+			urls := photos[batch[i].ID]
+			if urls == nil {
+				urls = []string{}
+			}
+			if err := enc.Encode(exportRecord{Property: batch[i], PhotoURLs: urls}); err != nil {
+				return err
+			}
+		}
+		c.Writer.Flush()
+		return nil
+	})
+}
+
+// exportXLSX memakai StreamWriter excelize, baris yang sudah ditulis disimpan di file
+// sementara oleh excelize sehingga tidak semua baris ditahan di memory
+func (h *PropertyHandler) exportXLSX(c *gin.Context, params utils.QueryParams) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := "Properties"
+	f.SetSheetName("Sheet1", sheet)
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	header := make([]interface{}, len(exportColumns))
+	for i, col := range exportColumns {
+		header[i] = col
+	}
+	if err := sw.SetRow("A1", header); err != nil {
+		return err
+	}
+
+	rowNum := 2
+	err = h.repo.ExportProperties(params, func(batch []models.Property, photos map[uint][]string) error {
+		for i := range batch {
+			values := exportRow(&batch[i], photos[batch[i].ID])
+			row := make([]interface{}, len(values))
+			for j, v := range values {
+				row[j] = v
+			}
+			cell, err := excelize.CoordinatesToCellName(1, rowNum)
+			if err != nil {
+				return err
+			}
+			if err := sw.SetRow(cell, row); err != nil {
+				return err
+			}
+			rowNum++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+	return f.Write(c.Writer)
+}
+
+// exportRow mengubah property menjadi satu baris sesuai exportColumns.
+// Kolom teks dari user lewat utils.SafeCell supaya tidak terbaca sebagai formula.
+func exportRow(p *models.Property, photoURLs []string) []string {
+	amenities := make([]string, len(p.Amenities))
+	for i, a := range p.Amenities {
+		amenities[i] = a.Slug
+	}
+
+	return []string{
+		strconv.FormatUint(uint64(p.ID), 10),
+		utils.SafeCell(p.Title),
+		utils.SafeCell(p.Description),
+		p.ListingType,
+		strconv.FormatInt(p.Price, 10),
+		p.Currency,
+		strconv.FormatBool(p.PriceNegotiable),
+		strconv.FormatBool(p.PriceHidden),
+		p.PricePeriod,
+		strconv.Itoa(p.MinLeaseMonths),
+		strconv.FormatInt(p.Deposit, 10),
+		strconv.FormatInt(p.MonthlyPrice, 10),
+		strconv.Itoa(p.LandSize),
+		strconv.Itoa(p.BuildingSize),
+		strconv.Itoa(p.Bedrooms),
+		strconv.Itoa(p.Bathrooms),
+		strconv.Itoa(p.Floors),
+		utils.SafeCell(p.Certificate),
+		strconv.Itoa(p.Electricity),
+		utils.SafeCell(p.WaterSource),
+		utils.SafeCell(p.Address),
+		strings.Join(amenities, ","),
+		utils.SafeCell(p.PhotoPath),
+		strings.Join(photoURLs, " "),
+		p.CreatedAt.Format(time.RFC3339),
+		p.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func setDownloadHeaders(c *gin.Context, contentType, filename string) {
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,400 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"project-zero/internal/models"
+	"project-zero/pkg/database"
+	"project-zero/pkg/rupiah"
+	"project-zero/pkg/utils"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+// Batas import supaya satu request tidak terlalu berat
+const (
+	MaxImportFileSize   = 10 * 1024 * 1024 // 10MB
+	MaxImportFileSizeMB = 10
+	MaxImportRows       = 5000
+)
+
+// importFieldSetters daftar kolom yang bisa diimport, key = nama field JSON di models.Property
+var importFieldSetters = map[string]func(p *models.Property, value string) error{
+	"title":              func(p *models.Property, v string) error { p.Title = v; return nil },
+	"description":        func(p *models.Property, v string) error { p.Description = v; return nil },
+	"listing_type":       func(p *models.Property, v string) error { p.ListingType = strings.ToUpper(v); return nil },
+	"price":              func(p *models.Property, v string) error { return setAmount(&p.Price, v) },
+	"currency":           func(p *models.Property, v string) error { p.Currency = strings.ToUpper(v); return nil },
+	"price_negotiable":   func(p *models.Property, v string) error { return setBool(&p.PriceNegotiable, v) },
+	"price_hidden":       func(p *models.Property, v string) error { return setBool(&p.PriceHidden, v) },
+	"price_period":       func(p *models.Property, v string) error { p.PricePeriod = strings.ToLower(v); return nil },
+	"min_lease_months":   func(p *models.Property, v string) error { return setInt(&p.MinLeaseMonths, v) },
+	"deposit":            func(p *models.Property, v string) error { return setAmount(&p.Deposit, v) },
+	"included_utilities": func(p *models.Property, v string) error { p.IncludedUtilities = splitList(v); return nil },
+	"land_size":          func(p *models.Property, v string) error { return setInt(&p.LandSize, v) },
+	"building_size":      func(p *models.Property, v string) error { return setInt(&p.BuildingSize, v) },
+	"bedrooms":           func(p *models.Property, v string) error { return setInt(&p.Bedrooms, v) },
+	"bathrooms":          func(p *models.Property, v string) error { return setInt(&p.Bathrooms, v) },
+	"floors":             func(p *models.Property, v string) error { return setInt(&p.Floors, v) },
+	"certificate":        func(p *models.Property, v string) error { p.Certificate = strings.ToUpper(v); return nil },
+	"electricity":        func(p *models.Property, v string) error { return setInt(&p.Electricity, v) },
+	"water_source":       func(p *models.Property, v string) error { p.WaterSource = v; return nil },
+	"address":            func(p *models.Property, v string) error { p.Address = v; return nil },
+	"photo_path":         func(p *models.Property, v string) error { p.PhotoPath = v; return nil },
+}
+
+// importAmenitiesField kolom berisi slug amenity dipisah koma, contoh: "pool,carport"
+const importAmenitiesField = "amenities"
+
+type PropertyImportHandler struct {
+	repo      *database.PropertyRepository
+	amenities *database.AmenityRepository
+}
+
+// NewPropertyImportHandler membuat instance baru PropertyImportHandler
+func NewPropertyImportHandler(repo *database.PropertyRepository, amenities *database.AmenityRepository) *PropertyImportHandler {
+	return &PropertyImportHandler{repo: repo, amenities: amenities}
+}
+
+// ImportProperties import banyak listing dari CSV/XLSX.
+// Form field: file, mapping (JSON {"field": "Nama Kolom"}, optional), dry_run (true/false).
+// Baris yang valid disimpan dalam satu transaksi, baris yang tidak valid dilaporkan per baris.
+func (h *PropertyImportHandler) ImportProperties(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal ambil file"})
+		return
+	}
+	if file.Size > MaxImportFileSize {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Ukuran file terlalu besar, maksimal " + strconv.Itoa(MaxImportFileSizeMB) + "MB",
+		})
+		return
+	}
+
+	mapping := map[string]string{}
+	if raw := c.PostForm("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Mapping kolom tidak valid", "details": err.Error()})
+			return
+		}
+	}
+
+	rows, err := utils.ReadSpreadsheet(file, MaxImportRows)
+	if err == utils.ErrTooManyRows {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Terlalu banyak baris, maksimal " + strconv.Itoa(MaxImportRows)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(rows) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File tidak punya data (baris pertama harus header)"})
+		return
+	}
+
+	columns, err := resolveImportColumns(rows[0], mapping)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Mapping kolom tidak valid", "details": err.Error()})
+		return
+	}
+
+	catalog, err := h.amenities.GetAllAmenities()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+	amenityIDs := make(map[string]uint, len(catalog))
+	for _, a := range catalog {
+		amenityIDs[a.Slug] = a.ID
+	}
+
+	record := &models.PropertyImport{
+		UserID:   userID.(uint),
+		FileName: file.Filename,
+		DryRun:   utils.ParseBool(c.PostForm("dry_run")),
+	}
+	valid := make(map[int]*models.Property)
+
+	for i, row := range rows[1:] {
+		if isBlankRow(row) {
+			continue
+		}
+
+		property, rowErrors := buildImportProperty(row, columns, amenityIDs)
+		property.UserID = record.UserID
+
+		result := models.PropertyImportRow{Row: i + 2, Title: property.Title, Status: models.ImportRowValid}
+		if len(rowErrors) > 0 {
+			result.Status = models.ImportRowInvalid
+			result.Errors = rowErrors
+		} else {
+			valid[len(record.Rows)] = property
+		}
+		record.Rows = append(record.Rows, result)
+	}
+	record.TotalRows = len(record.Rows)
+	record.ValidRows = len(valid)
+
+	if record.DryRun || len(valid) == 0 {
+		err = h.repo.SaveImport(record)
+	} else {
+		err = h.repo.ImportProperties(record, valid)
+	}
+	if err != nil {
+		if err == database.ErrUnknownAmenity {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal menyimpan data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	status := http.StatusOK
+	if record.ImportedRows > 0 {
+		status = http.StatusCreated
+	}
+	c.JSON(status, gin.H{
+		"data":       record,
+		"report_url": fmt.Sprintf("/properties/imports/%d/report", record.ID),
+	})
+}
+
+// GetImport mengambil hasil import milik user
+func (h *PropertyImportHandler) GetImport(c *gin.Context) {
+	record, ok := h.findOwnImport(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": record})
+}
+
+// DownloadImportReport download laporan hasil import per baris dalam bentuk CSV
+func (h *PropertyImportHandler) DownloadImportReport(c *gin.Context) {
+	record, ok := h.findOwnImport(c)
+	if !ok {
+		return
+	}
+
+	setDownloadHeaders(c, "text/csv; charset=utf-8", fmt.Sprintf("import-%d-report.csv", record.ID))
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"row", "status", "property_id", "title", "errors"})
+	for _, row := range record.Rows {
+		propertyID := ""
+		if row.PropertyID > 0 {
+			propertyID = strconv.FormatUint(uint64(row.PropertyID), 10)
+		}
+		w.Write([]string{strconv.Itoa(row.Row), row.Status, propertyID, utils.SafeCell(row.Title), utils.SafeCell(strings.Join(row.Errors, "; "))})
+	}
+	w.Flush()
+}
+
+func (h *PropertyImportHandler) findOwnImport(c *gin.Context) (*models.PropertyImport, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return nil, false
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return nil, false
+	}
+
+	record, err := h.repo.GetImport(uint(id))
+	if err != nil || record.UserID != userID.(uint) {
+		if err != nil && err != gorm.ErrRecordNotFound {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		} else {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		}
+		return nil, false
+	}
+	return record, true
+}
+
+// resolveImportColumns mencari index kolom untuk setiap field.
+// Tanpa mapping, header dicocokkan dengan nama field (case-insensitive).
+func resolveImportColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for field := range mapping {
+		if _, ok := importFieldSetters[field]; !ok && field != importAmenitiesField {
+			return nil, fmt.Errorf("field %q tidak bisa diimport", field)
+		}
+	}
+
+	columns := make(map[string]int)
+	fields := []string{importAmenitiesField}
+	for field := range importFieldSetters {
+		fields = append(fields, field)
+	}
+	for _, field := range fields {
+		column, mapped := mapping[field]
+		if !mapped {
+			column = field
+		}
+		i, ok := index[strings.ToLower(strings.TrimSpace(column))]
+		if !ok {
+			if mapped {
+				return nil, fmt.Errorf("kolom %q untuk field %s tidak ada di header", column, field)
+			}
+			continue
+		}
+		columns[field] = i
+	}
+	return columns, nil
+}
+
+// buildImportProperty mengubah satu baris menjadi Property dan memvalidasinya
+// dengan aturan binding yang sama seperti CreateProperty
+func buildImportProperty(row []string, columns map[string]int, amenityIDs map[string]uint) (*models.Property, []string) {
+	property := &models.Property{}
+	var rowErrors []string
+
+	for field, i := range columns {
+		if i >= len(row) {
+			continue
+		}
+		value := utils.UnsafeCell(strings.TrimSpace(row[i]))
+		if value == "" {
+			continue
+		}
+
+		if field == importAmenitiesField {
+			for _, slug := range splitList(strings.ToLower(value)) {
+				id, ok := amenityIDs[slug]
+				if !ok {
+					rowErrors = append(rowErrors, fmt.Sprintf("amenities: %q tidak terdaftar", slug))
+					continue
+				}
+				property.AmenityIDs = append(property.AmenityIDs, id)
+			}
+			continue
+		}
+
+		if err := importFieldSetters[field](property, value); err != nil {
+			rowErrors = append(rowErrors, fmt.Sprintf("%s: %v", field, err))
+		}
+	}
+
+	if err := binding.Validator.ValidateStruct(property); err != nil {
+		rowErrors = append(rowErrors, validationMessages(err)...)
+	}
+	if err := property.ValidateRentalTerms(); err != nil {
+		rowErrors = append(rowErrors, err.Error())
+	}
+	property.NormalizeRentalPrice()
+	if property.Currency == "" {
+		property.Currency = utils.DefaultCurrency
+	}
+
+	return property, rowErrors
+}
+
+// validationMessages mengubah error validator menjadi pesan per field JSON
+func validationMessages(err error) []string {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return []string{err.Error()}
+	}
+
+	propertyType := reflect.TypeOf(models.Property{})
+	messages := make([]string, 0, len(verrs))
+	for _, fe := range verrs {
+		name := fe.Field()
+		if sf, ok := propertyType.FieldByName(fe.StructField()); ok {
+			if tag := strings.Split(sf.Tag.Get("json"), ",")[0]; tag != "" {
+				name = tag
+			}
+		}
+		rule := fe.Tag()
+		if fe.Param() != "" {
+			rule += "=" + fe.Param()
+		}
+		messages = append(messages, fmt.Sprintf("%s: gagal validasi '%s'", name, rule))
+	}
+	return messages
+}
+
+// thousandsPattern angka bulat dengan pemisah ribuan titik, contoh "1.200"
+var thousandsPattern = regexp.MustCompile(`^[0-9]{1,3}(\.[0-9]{3})+$`)
+
+// setInt hanya menerima bilangan bulat. Pemisah ribuan titik boleh ("1.200"), tapi desimal
+// seperti "120,5" ditolak supaya tidak terbaca 1205.
+func setInt(dst *int, value string) error {
+	if thousandsPattern.MatchString(value) {
+		value = strings.ReplaceAll(value, ".", "")
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%q bukan bilangan bulat", value)
+	}
+	*dst = n
+	return nil
+}
+
+func setAmount(dst *int64, value string) error {
+	n, err := rupiah.Parse(value)
+	if err != nil {
+		return fmt.Errorf("%q: %v", value, err)
+	}
+	*dst = n
+	return nil
+}
+
+func setBool(dst *bool, value string) error {
+	switch strings.ToLower(value) {
+	case "1", "true", "ya", "y", "yes":
+		*dst = true
+	case "0", "false", "tidak", "n", "no":
+		*dst = false
+	default:
+		return fmt.Errorf("%q bukan ya/tidak", value)
+	}
+	return nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func isBlankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
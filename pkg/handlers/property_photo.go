@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"project-zero/internal/models"
+	"project-zero/pkg/database"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -10,22 +14,67 @@ import (
 )
 
 type PropertyPhotoHandler struct {
-	db *gorm.DB
+	db     *gorm.DB
+	photos *database.PropertyPhotoRepository
+	assets *database.MediaAssetRepository
 }
 
 func NewPropertyPhotoHandler(db *gorm.DB) *PropertyPhotoHandler {
-	return &PropertyPhotoHandler{db: db}
+	return &PropertyPhotoHandler{
+		db:     db,
+		photos: database.NewPropertyPhotoRepository(db),
+		assets: database.NewMediaAssetRepository(db),
+	}
+}
+
+type ReorderPhotosRequest struct {
+	PhotoIDs []uint `json:"photo_ids" binding:"required,min=1"`
 }
 
-// AddPropertyPhoto menambahkan foto tambahan ke properti
+type UpdatePhotoCaptionRequest struct {
+	Caption string `json:"caption" binding:"max=500"`
+}
+
+// AddPropertyPhoto menambahkan media ke properti: foto (default), denah, video, foto 360
+// atau link virtual tour sesuai field kind
 func (h *PropertyPhotoHandler) AddPropertyPhoto(c *gin.Context) {
 	var input models.PropertyPhoto
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
 		return
 	}
+	if _, ok := h.ownedPropertyByID(c, input.PropertyID); !ok {
+		return
+	}
+	if input.Kind == models.MediaKindTour && !isTourURL(input.PhotoPath) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": "Link virtual tour harus URL https"})
+		return
+	}
 
-	if err := h.db.Create(&input).Error; err != nil {
+	err := h.photos.AddPhoto(&input)
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Property not found"})
+		return
+	}
+	if err == database.ErrPhotoLimitReached {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "PHOTO_LIMIT_REACHED"})
+		return
+	}
+	var limitErr *database.MediaLimitError
+	if errors.As(err, &limitErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "MEDIA_LIMIT_REACHED"})
+		return
+	}
+	switch err {
+	case database.ErrVideoAssetRequired, database.ErrVideoNotAllowed, database.ErrNotPanorama, database.ErrTourIsUpload, database.ErrMediaAssetRequired:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": err.Error()})
+		return
+	}
+	if err == database.ErrDuplicatePhoto {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "DUPLICATE_PHOTO"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save photo", "details": err.Error()})
 		return
 	}
@@ -33,12 +82,12 @@ func (h *PropertyPhotoHandler) AddPropertyPhoto(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"data": input})
 }
 
-// GetPropertyPhotos mengambil semua foto dari properti tertentu
+// GetPropertyPhotos mengambil semua foto dari properti tertentu, urut sesuai posisi galeri
 func (h *PropertyPhotoHandler) GetPropertyPhotos(c *gin.Context) {
 	propertyID := c.Param("property_id")
 
 	var photos []models.PropertyPhoto
-	if err := h.db.Where("property_id = ?", propertyID).Find(&photos).Error; err != nil {
+	if err := h.db.Where("property_id = ?", propertyID).Order("position ASC, id ASC").Find(&photos).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch photos"})
 		return
 	}
@@ -46,19 +95,229 @@ func (h *PropertyPhotoHandler) GetPropertyPhotos(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": photos})
 }
 
+// ReorderPropertyPhotos mengatur ulang urutan galeri dalam satu request.
+// photo_ids harus berisi semua foto listing tersebut, urut dari posisi pertama.
+func (h *PropertyPhotoHandler) ReorderPropertyPhotos(c *gin.Context) {
+	property, ok := h.ownedProperty(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var req ReorderPhotosRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": err.Error()})
+		return
+	}
+
+	var photos []models.PropertyPhoto
+	if err := h.db.Where("property_id = ?", property.ID).Find(&photos).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch photos"})
+		return
+	}
+
+	existing := make(map[uint]bool, len(photos))
+	for _, photo := range photos {
+		existing[photo.ID] = true
+	}
+	seen := make(map[uint]bool, len(req.PhotoIDs))
+	for _, id := range req.PhotoIDs {
+		if !existing[id] || seen[id] {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validasi gagal",
+				"details": fmt.Sprintf("photo_id %d tidak valid atau duplikat untuk listing ini", id),
+			})
+			return
+		}
+		seen[id] = true
+	}
+	if len(seen) != len(existing) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validasi gagal",
+			"details": fmt.Sprintf("photo_ids harus berisi semua %d foto listing ini", len(existing)),
+		})
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		for position, id := range req.PhotoIDs {
+			if err := tx.Model(&models.PropertyPhoto{}).Where("id = ?", id).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder photos", "details": err.Error()})
+		return
+	}
+
+	h.respondPhotos(c, property.ID)
+}
+
+// SetCoverPhoto menjadikan foto sebagai cover dan menyamakan Property.PhotoPath.
+// Hanya media kind photo yang bisa jadi cover.
+func (h *PropertyPhotoHandler) SetCoverPhoto(c *gin.Context) {
+	photo, ok := h.ownedPhoto(c)
+	if !ok {
+		return
+	}
+	if photo.Kind != models.MediaKindPhoto {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": "Hanya foto yang bisa dijadikan cover"})
+		return
+	}
+
+	if err := h.db.Transaction(func(tx *gorm.DB) error {
+		return setCover(tx, photo.PropertyID, photo)
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set cover photo", "details": err.Error()})
+		return
+	}
+
+	h.respondPhotos(c, photo.PropertyID)
+}
+
+// UpdatePhotoCaption mengubah caption foto
+func (h *PropertyPhotoHandler) UpdatePhotoCaption(c *gin.Context) {
+	photo, ok := h.ownedPhoto(c)
+	if !ok {
+		return
+	}
+
+	var req UpdatePhotoCaptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": err.Error()})
+		return
+	}
+
+	if err := h.db.Model(photo).Update("caption", req.Caption).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update caption", "details": err.Error()})
+		return
+	}
+	photo.Caption = req.Caption
+
+	c.JSON(http.StatusOK, gin.H{"data": photo})
+}
+
 // DeletePropertyPhoto menghapus foto tambahan
 func (h *PropertyPhotoHandler) DeletePropertyPhoto(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+	photo, ok := h.ownedPhoto(c)
+	if !ok {
 		return
 	}
 
-	if err := h.db.Delete(&models.PropertyPhoto{}, id).Error; err != nil {
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(photo).Error; err != nil {
+			return err
+		}
+		if !photo.IsCover {
+			return nil
+		}
+
+		// Cover dihapus: foto berikutnya jadi cover, kalau tidak ada foto lagi PhotoPath dikosongkan
+		var next models.PropertyPhoto
+		err := tx.Where("property_id = ? AND kind = ?", photo.PropertyID, models.MediaKindPhoto).Order("position ASC, id ASC").First(&next).Error
+		if err == gorm.ErrRecordNotFound {
+			return tx.Model(&models.Property{}).Where("id = ? AND photo_path = ?", photo.PropertyID, photo.PhotoPath).
+				Update("photo_path", "").Error
+		}
+		if err != nil {
+			return err
+		}
+		return setCover(tx, photo.PropertyID, &next)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete photo"})
 		return
 	}
 
+	// File yang tercatat sebagai MediaAsset dibersihkan GC (termasuk rendition) setelah grace period.
+	// File lama tanpa MediaAsset tidak dihapus dari sini: pemiliknya tidak bisa dipastikan dari URL,
+	// jadi listing lain tidak boleh bisa menghapus file milik user lain. Kalau gagal cukup dicatat, row sudah terhapus.
+	if err := h.assets.RefreshAttachment(photo.PhotoPath); err != nil {
+		fmt.Printf("⚠️  Gagal update status asset %s: %v\n", photo.PhotoPath, err)
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Photo deleted successfully"})
 }
+
+// setCover menandai satu foto sebagai cover listing dan menyalin path-nya ke Property.PhotoPath
+func setCover(tx *gorm.DB, propertyID uint, photo *models.PropertyPhoto) error {
+	if err := tx.Model(&models.PropertyPhoto{}).Where("property_id = ? AND id <> ?", propertyID, photo.ID).
+		Update("is_cover", false).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(photo).Update("is_cover", true).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.Property{}).Where("id = ?", propertyID).Update("photo_path", photo.PhotoPath).Error
+}
+
+// ownedProperty mengambil property berdasarkan ID dan memastikan milik user yang login
+func (h *PropertyPhotoHandler) ownedProperty(c *gin.Context, idStr string) (*models.Property, bool) {
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return nil, false
+	}
+	return h.ownedPropertyByID(c, uint(id))
+}
+
+// ownedPropertyByID seperti ownedProperty untuk ID yang sudah berupa angka (contoh dari body JSON)
+func (h *PropertyPhotoHandler) ownedPropertyByID(c *gin.Context, id uint) (*models.Property, bool) {
+	var property models.Property
+	if err := h.db.First(&property, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Property not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch property"})
+		}
+		return nil, false
+	}
+
+	userID, _ := c.Get("userID")
+	if uid, ok := userID.(uint); !ok || property.UserID != uid {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Bukan listing milik kamu"})
+		return nil, false
+	}
+	return &property, true
+}
+
+// ownedPhoto mengambil foto dari param :id dan memastikan listing-nya milik user yang login
+func (h *PropertyPhotoHandler) ownedPhoto(c *gin.Context) (*models.PropertyPhoto, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return nil, false
+	}
+
+	var photo models.PropertyPhoto
+	if err := h.db.First(&photo, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Photo not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch photo"})
+		}
+		return nil, false
+	}
+
+	if _, ok := h.ownedPropertyByID(c, photo.PropertyID); !ok {
+		return nil, false
+	}
+	return &photo, true
+}
+
+// respondPhotos mengirim galeri terbaru listing
+func (h *PropertyPhotoHandler) respondPhotos(c *gin.Context, propertyID uint) {
+	var photos []models.PropertyPhoto
+	if err := h.db.Where("property_id = ?", propertyID).Order("position ASC, id ASC").Find(&photos).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch photos"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": photos})
+}
+
+// isTourURL link virtual tour harus URL https absolut
+func isTourURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && u.Scheme == "https" && u.Host != ""
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"net/http"
+	"project-zero/internal/models"
+	"project-zero/pkg/database"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PropertyVerificationHandler struct {
+	verifications *database.PropertyVerificationRepository
+	photos        *database.PropertyPhotoRepository
+}
+
+// NewPropertyVerificationHandler membuat instance baru PropertyVerificationHandler
+func NewPropertyVerificationHandler(verifications *database.PropertyVerificationRepository, photos *database.PropertyPhotoRepository) *PropertyVerificationHandler {
+	return &PropertyVerificationHandler{verifications: verifications, photos: photos}
+}
+
+// SubmitVerificationRequest dokumen listing yang dilampirkan untuk verifikasi,
+// minimal satu harus berjenis certificate
+type SubmitVerificationRequest struct {
+	DocumentIDs []uint `json:"document_ids" binding:"required,min=1,max=20"`
+	Notes       string `json:"notes" binding:"max=1000"`
+}
+
+// ReviewVerificationRequest catatan reviewer, wajib diisi kalau menolak
+type ReviewVerificationRequest struct {
+	Notes string `json:"notes" binding:"max=1000"`
+}
+
+// SubmitVerification mengajukan verifikasi sertifikat listing milik user yang login
+func (h *PropertyVerificationHandler) SubmitVerification(c *gin.Context) {
+	propertyID, ok := ownedPropertyID(c, h.photos, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var req SubmitVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validasi gagal",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	uid, _ := userID.(uint)
+	verification := models.PropertyVerification{
+		PropertyID: propertyID,
+		UserID:     uid,
+		Notes:      strings.TrimSpace(req.Notes),
+	}
+	if err := h.verifications.SubmitVerification(&verification, req.DocumentIDs); err != nil {
+		switch err {
+		case database.ErrVerificationNoCertificate, database.ErrVerificationDocument:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": err.Error()})
+		case database.ErrAlreadyVerified:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "ALREADY_VERIFIED"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan data", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": verification})
+}
+
+// GetPropertyVerifications mengambil riwayat pengajuan verifikasi listing milik user yang login
+func (h *PropertyVerificationHandler) GetPropertyVerifications(c *gin.Context) {
+	propertyID, ok := ownedPropertyID(c, h.photos, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	verifications, err := h.verifications.GetPropertyVerifications(propertyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": verifications})
+}
+
+// GetVerifications mengambil antrian pengajuan verifikasi (admin only).
+// Default hanya yang pending, ?status=all untuk semua.
+func (h *PropertyVerificationHandler) GetVerifications(c *gin.Context) {
+	status := c.DefaultQuery("status", models.VerificationRequestPending)
+	switch status {
+	case "all":
+		status = ""
+	case models.VerificationRequestPending, models.VerificationRequestApproved,
+		models.VerificationRequestRejected, models.VerificationRequestCancelled:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validasi gagal",
+			"details": "status harus pending, approved, rejected, cancelled atau all",
+		})
+		return
+	}
+
+	verifications, err := h.verifications.GetVerifications(status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": verifications})
+}
+
+// GetVerification mengambil satu pengajuan beserta dokumennya (admin only)
+func (h *PropertyVerificationHandler) GetVerification(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return
+	}
+
+	verification, err := h.verifications.GetVerificationByID(uint(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": verification})
+}
+
+// ApproveVerification menyetujui pengajuan, listing mendapat badge "verified legal" (admin only)
+func (h *PropertyVerificationHandler) ApproveVerification(c *gin.Context) {
+	h.review(c, true)
+}
+
+// RejectVerification menolak pengajuan dengan catatan alasan (admin only)
+func (h *PropertyVerificationHandler) RejectVerification(c *gin.Context) {
+	h.review(c, false)
+}
+
+func (h *PropertyVerificationHandler) review(c *gin.Context, approve bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return
+	}
+
+	// Body boleh kosong untuk approve tanpa catatan
+	var req ReviewVerificationRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validasi gagal",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+	req.Notes = strings.TrimSpace(req.Notes)
+	if !approve && req.Notes == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validasi gagal",
+			"details": "notes wajib diisi untuk menolak pengajuan",
+		})
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	reviewerID, _ := userID.(uint)
+	verification, err := h.verifications.ReviewVerification(uint(id), reviewerID, approve, req.Notes)
+	if err != nil {
+		switch err {
+		case gorm.ErrRecordNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		case database.ErrVerificationNotPending:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "VERIFICATION_NOT_PENDING"})
+		case database.ErrVerificationStale:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "CERTIFICATE_CHANGED"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan data", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": verification})
+}
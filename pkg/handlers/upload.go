@@ -0,0 +1,508 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"path/filepath"
+	"project-zero/internal/models"
+	"project-zero/pkg/database"
+	"project-zero/pkg/imaging"
+	"project-zero/pkg/storage"
+	"project-zero/pkg/video"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Constants untuk image validation
+const (
+	MaxFileSize   = 100 * 1024 * 1024 // 100MB
+	MaxFileSizeMB = 100
+
+	MaxImageDimension = imaging.MaxImageDimension // Sisi terpanjang maksimal (pixel)
+	MaxImagePixels    = imaging.MaxImagePixels    // Total pixel maksimal (60MP), mencegah decompression bomb
+)
+
+// AllowedImageTypes tipe MIME yang diterima, dideteksi dari isi file (magic bytes), bukan extension
+var AllowedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// PhotoFolder folder storage untuk foto properti
+const PhotoFolder = "property-photos"
+
+// OriginalFolder folder storage private untuk foto asli tanpa watermark
+const OriginalFolder = "originals"
+
+// OriginalLinkExpiry masa berlaku link download foto asli tanpa watermark
+const OriginalLinkExpiry = 15 * time.Minute
+
+type UploadHandler struct {
+	store         storage.Storage
+	private       storage.Storage
+	assets        *database.MediaAssetRepository
+	photos        *database.PropertyPhotoRepository
+	settings      *database.SettingRepository
+	organizations *database.OrganizationRepository
+	processor     *imaging.Processor
+	video         *video.Tool
+}
+
+// NewUploadHandler membuat instance baru UploadHandler
+func NewUploadHandler(store, private storage.Storage, assets *database.MediaAssetRepository, photos *database.PropertyPhotoRepository, settings *database.SettingRepository, organizations *database.OrganizationRepository, processor *imaging.Processor, video *video.Tool) *UploadHandler {
+	return &UploadHandler{store: store, private: private, assets: assets, photos: photos, settings: settings, organizations: organizations, processor: processor, video: video}
+}
+
+// UploadFile menghandle upload file dengan validasi dan upload ke storage (Cloudinary, local atau S3).
+// Kalau form property_id diisi, foto dicek dengan galeri listing tersebut: foto identik ditolak,
+// foto yang mirip tetap disimpan dengan peringatan di field duplicates.
+func (h *UploadHandler) UploadFile(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal ambil file"})
+		return
+	}
+
+	var propertyID uint
+	if raw := c.PostForm("property_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property_id"})
+			return
+		}
+		propertyID = uint(id)
+	}
+
+	// Validate file
+	if err := validateImageFile(file); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal membuka file"})
+		return
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(src)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal membaca file"})
+		return
+	}
+
+	// Validasi isi file: tipe dari magic bytes dan dimensi dari header gambar
+	if err := validateImageContent(data); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	asset := models.MediaAsset{Status: models.MediaStatusReady, OriginalName: file.Filename}
+	if userID, exists := c.Get("userID"); exists {
+		asset.UserID = userID.(uint)
+	}
+	if propertyID > 0 && !h.checkListingOwner(c, propertyID, asset.UserID) {
+		return
+	}
+	uploaded, duplicates, err := h.processAndStore(ctx, file.Filename, data, &asset, propertyID)
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+
+	if err := h.assets.CreateAsset(&asset); err != nil {
+		h.cleanup(ctx, uploaded)
+		h.cleanupPrivate(ctx, asset.OriginalKey)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal menyimpan data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Return URL untuk database
+	response := uploadResponse(&asset)
+	response["message"] = "File berhasil diupload"
+	addDuplicateWarning(response, duplicates)
+	c.JSON(http.StatusOK, response)
+}
+
+// processAndStore membuang metadata foto (EXIF/GPS, XMP, IPTC), membuat rendition (thumb, card, full)
+// lalu menyimpan file asli dan semua rendition ke storage. Hasilnya diisi ke asset tanpa disimpan
+// ke database; key yang sudah diupload dikembalikan supaya bisa dihapus kalau langkah berikutnya gagal.
+// Kalau propertyID diisi, foto yang mirip di listing itu dikembalikan dan foto identik ditolak
+// dengan DuplicatePhotoError sebelum apa pun disimpan.
+func (h *UploadHandler) processAndStore(ctx context.Context, filename string, data []byte, asset *models.MediaAsset, propertyID uint) ([]string, []database.DuplicateMatch, error) {
+	keepTags, err := h.settings.GetMetadataAllowlist()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Gagal mengambil pengaturan: %v", err)
+	}
+
+	watermark, err := h.watermarkFor(ctx, asset.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := h.processor.Process(data, keepTags, watermark)
+	if err != nil {
+		return nil, nil, &ValidationError{Code: "INVALID_IMAGE", Message: err.Error()}
+	}
+
+	var duplicates []database.DuplicateMatch
+	if propertyID > 0 {
+		duplicates, err = h.assets.FindListingDuplicates(propertyID, result.Hash, imaging.NearDuplicateDistance)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Gagal cek foto duplikat: %v", err)
+		}
+		if len(duplicates) > 0 && duplicates[0].Exact {
+			return nil, nil, &DuplicatePhotoError{Matches: duplicates}
+		}
+	}
+
+	// Foto ber-watermark: foto asli bersih disimpan di storage private, URL publik memakai salinan ber-watermark
+	original := result.Original
+	public := original
+	originalKey := ""
+	if result.Public != nil {
+		public = *result.Public
+		originalKey = storage.NewKey(OriginalFolder, strings.TrimSuffix(filename, filepath.Ext(filename))+original.Ext())
+		if _, err := h.private.Put(ctx, originalKey, bytes.NewReader(original.Data), int64(len(original.Data)), original.ContentType); err != nil {
+			return nil, nil, fmt.Errorf("Gagal upload foto asli ke storage private: %v", err)
+		}
+	}
+	key := storage.NewKey(PhotoFolder, strings.TrimSuffix(filename, filepath.Ext(filename))+public.Ext())
+	photoURL, err := h.store.Put(ctx, key, bytes.NewReader(public.Data), int64(len(public.Data)), public.ContentType)
+	if err != nil {
+		h.cleanupPrivate(ctx, originalKey)
+		return nil, nil, fmt.Errorf("Gagal upload ke storage: %v", err)
+	}
+
+	uploaded := []string{key}
+	renditions := make(models.ImageRenditions, 0, len(result.Renditions))
+	for _, out := range result.Renditions {
+		renditionKey := renditionKey(key, out)
+		url, err := h.store.Put(ctx, renditionKey, bytes.NewReader(out.Data), int64(len(out.Data)), out.ContentType)
+		if err != nil {
+			h.cleanup(ctx, uploaded)
+			h.cleanupPrivate(ctx, originalKey)
+			return nil, nil, fmt.Errorf("Gagal upload rendition ke storage: %v", err)
+		}
+		uploaded = append(uploaded, renditionKey)
+		renditions = append(renditions, models.ImageRendition{Name: out.Name, URL: url, Width: out.Width, Height: out.Height})
+	}
+
+	asset.URL = photoURL
+	asset.StorageKey = key
+	asset.ContentType = public.ContentType
+	asset.Size = int64(len(public.Data))
+	asset.Watermarked = result.Public != nil
+	asset.OriginalKey = originalKey
+	asset.Width = result.Width
+	asset.Height = result.Height
+	asset.Renditions = renditions
+	asset.PHash = int64(result.Hash)
+	asset.MetadataStripped = result.Metadata.Stripped
+	asset.MetadataRemoved = result.Metadata.Removed
+	asset.MetadataKept = result.Metadata.Kept
+	return uploaded, duplicates, nil
+}
+
+// checkListingOwner memastikan listing ada dan milik user, kalau tidak response error langsung dikirim
+func (h *UploadHandler) checkListingOwner(c *gin.Context, propertyID, userID uint) bool {
+	ownerID, err := h.photos.GetPropertyOwnerID(propertyID)
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return false
+	}
+	if ownerID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Bukan listing milik kamu"})
+		return false
+	}
+	return true
+}
+
+// addDuplicateWarning menambahkan peringatan foto mirip ke response upload
+func addDuplicateWarning(response gin.H, duplicates []database.DuplicateMatch) {
+	if len(duplicates) == 0 {
+		return
+	}
+	response["warning"] = "Foto ini mirip dengan foto yang sudah ada di listing"
+	response["duplicates"] = duplicates
+}
+
+// uploadResponse isi response upload yang sama untuk upload biasa, upload langsung dan video
+func uploadResponse(asset *models.MediaAsset) gin.H {
+	response := gin.H{
+		"photo_path":    asset.URL,
+		"thumbnail_url": asset.Renditions.URL(models.RenditionThumb),
+		"width":         asset.Width,
+		"height":        asset.Height,
+		"renditions":    asset.Renditions,
+		"metadata": gin.H{
+			"stripped": asset.MetadataStripped,
+			"removed":  asset.MetadataRemoved,
+			"kept":     asset.MetadataKept,
+		},
+	}
+	if asset.IsVideo() {
+		response["content_type"] = asset.ContentType
+		response["duration"] = asset.Duration
+		response["poster_url"] = asset.Renditions.URL(models.RenditionPoster)
+	}
+	return response
+}
+
+// respondStoreError: ValidationError jadi 400, DuplicatePhotoError 409, selain itu kegagalan storage/database (500)
+func respondStoreError(c *gin.Context, err error) {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		respondValidationError(c, err)
+		return
+	}
+	var derr *DuplicatePhotoError
+	if errors.As(err, &derr) {
+		c.JSON(http.StatusConflict, gin.H{"error": derr.Error(), "code": "DUPLICATE_PHOTO", "duplicates": derr.Matches})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal upload ke storage", "details": err.Error()})
+}
+
+// renditionKey membuat key rendition dari key file asli, contoh: property-photos/x_rumah_card.jpg
+func renditionKey(key string, out imaging.Output) string {
+	return strings.TrimSuffix(key, path.Ext(key)) + "_" + out.Name + out.Ext()
+}
+
+// cleanup menghapus file yang sudah terlanjur diupload kalau proses upload gagal di tengah jalan
+func (h *UploadHandler) cleanup(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := h.store.Delete(ctx, key); err != nil {
+			fmt.Printf("⚠️  Gagal hapus file %s dari storage: %v\n", key, err)
+		}
+	}
+}
+
+// cleanupPrivate menghapus foto asli di storage private kalau proses upload gagal, key kosong diabaikan
+func (h *UploadHandler) cleanupPrivate(ctx context.Context, key string) {
+	if key == "" {
+		return
+	}
+	if err := h.private.Delete(ctx, key); err != nil {
+		fmt.Printf("⚠️  Gagal hapus file %s dari storage private: %v\n", key, err)
+	}
+}
+
+// watermarkFor menyiapkan watermark dari organisasi user, nil kalau tidak ada watermark aktif
+func (h *UploadHandler) watermarkFor(ctx context.Context, userID uint) (*imaging.Watermark, error) {
+	settings, err := h.organizations.GetUserWatermark(userID)
+	if err != nil {
+		return nil, fmt.Errorf("Gagal mengambil pengaturan watermark: %v", err)
+	}
+	if settings == nil {
+		return nil, nil
+	}
+
+	watermark := &imaging.Watermark{
+		Text:     settings.Text,
+		Position: settings.Position,
+		Opacity:  settings.Opacity,
+		Scale:    settings.Scale,
+	}
+	if settings.LogoURL == "" {
+		return watermark, nil
+	}
+
+	// Logo gagal dibaca: pakai teks kalau ada, supaya foto tidak pernah tampil tanpa watermark
+	logo, err := h.loadImage(ctx, settings.LogoURL)
+	if err != nil {
+		if settings.Text == "" {
+			return nil, fmt.Errorf("Gagal memuat logo watermark: %v", err)
+		}
+		fmt.Printf("⚠️  Gagal memuat logo watermark %s, pakai teks: %v\n", settings.LogoURL, err)
+		return watermark, nil
+	}
+	watermark.Logo = logo
+	return watermark, nil
+}
+
+// loadImage membaca dan decode gambar dari URL storage
+func (h *UploadHandler) loadImage(ctx context.Context, url string) (image.Image, error) {
+	key, ok := h.store.KeyFromURL(url)
+	if !ok {
+		return nil, fmt.Errorf("URL %s bukan file storage", url)
+	}
+	src, err := h.store.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	img, _, err := image.Decode(src)
+	return img, err
+}
+
+// GetOriginalLink membuat link sementara ke foto asli tanpa watermark, hanya untuk pemilik upload
+func (h *UploadHandler) GetOriginalLink(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	userID, _ := c.Get("userID")
+
+	asset, err := h.assets.GetUserAsset(uint(id), userID.(uint), models.MediaStatusReady)
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+
+	// Foto tanpa watermark: URL publiknya sudah foto asli
+	if asset.OriginalKey == "" {
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{"url": asset.URL, "watermarked": false}})
+		return
+	}
+
+	url, err := h.private.SignedURL(c.Request.Context(), asset.OriginalKey, OriginalLinkExpiry)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat link download", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"url":         url,
+		"watermarked": true,
+		"expires_at":  time.Now().Add(OriginalLinkExpiry),
+	}})
+}
+
+// ServeSignedFile menyajikan file local storage lewat signed URL (?expires=&signature=)
+func (h *UploadHandler) ServeSignedFile(c *gin.Context) {
+	serveLocalSigned(c, h.store)
+}
+
+// ServePrivateFile menyajikan file storage private (local) lewat signed URL
+func (h *UploadHandler) ServePrivateFile(c *gin.Context) {
+	serveLocalSigned(c, h.private)
+}
+
+func serveLocalSigned(c *gin.Context, store storage.Storage) {
+	local, ok := store.(*storage.LocalStorage)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		return
+	}
+
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if err := local.VerifySignature(key, c.Query("expires"), c.Query("signature")); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	path, err := local.Path(key)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if exists, _ := local.Exists(c.Request.Context(), key); !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		return
+	}
+	c.File(path)
+}
+
+// validateImageFile memvalidasi ukuran file sebelum isinya dibaca
+func validateImageFile(file *multipart.FileHeader) error {
+	if file.Size > MaxFileSize {
+		return &ValidationError{
+			Code:    "FILE_TOO_LARGE",
+			Message: "Ukuran file terlalu besar, maksimal " + strconv.Itoa(MaxFileSizeMB) + "MB",
+		}
+	}
+	return nil
+}
+
+// validateImageContent memvalidasi tipe file dari magic bytes lalu membaca header gambar
+// untuk membatasi dimensi sebelum gambar di-decode penuh
+func validateImageContent(data []byte) error {
+	mime := mimetype.Detect(data)
+	if !AllowedImageTypes[mime.String()] {
+		return &ValidationError{
+			Code:    "INVALID_FILE_TYPE",
+			Message: "Tipe file tidak didukung (" + mime.String() + "), gunakan: jpg, jpeg, png, gif, webp",
+		}
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return &ValidationError{
+			Code:    "INVALID_IMAGE",
+			Message: "File gambar rusak atau tidak bisa dibaca",
+		}
+	}
+
+	if config.Width <= 0 || config.Height <= 0 ||
+		config.Width > MaxImageDimension || config.Height > MaxImageDimension ||
+		int64(config.Width)*int64(config.Height) > MaxImagePixels {
+		return &ValidationError{
+			Code: "IMAGE_DIMENSIONS_TOO_LARGE",
+			Message: fmt.Sprintf("Dimensi gambar %dx%d terlalu besar, maksimal %d pixel per sisi dan %d megapixel",
+				config.Width, config.Height, MaxImageDimension, MaxImagePixels/1_000_000),
+		}
+	}
+	if mime.String() == "image/gif" {
+		if err := imaging.CheckGIFFrames(data); err != nil {
+			return &ValidationError{Code: "IMAGE_DIMENSIONS_TOO_LARGE", Message: err.Error()}
+		}
+	}
+	return nil
+}
+
+// respondValidationError mengirim ValidationError sebagai JSON lengkap dengan code-nya
+func respondValidationError(c *gin.Context, err error) {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": verr.Message, "code": verr.Code})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+// ValidationError custom error untuk validasi
+type ValidationError struct {
+	Code    string `json:"code"`
+	Message string `json:"error"`
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// DuplicatePhotoError foto upload identik dengan foto yang sudah ada di listing
+type DuplicatePhotoError struct {
+	Matches []database.DuplicateMatch
+}
+
+func (e *DuplicatePhotoError) Error() string {
+	return database.ErrDuplicatePhoto.Error()
+}
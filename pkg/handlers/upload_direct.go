@@ -0,0 +1,359 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"project-zero/internal/models"
+	"project-zero/pkg/database"
+	"project-zero/pkg/storage"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// DirectUploadExpiry masa berlaku parameter signed upload
+const DirectUploadExpiry = 15 * time.Minute
+
+// Folder file mentah dari upload langsung di storage private, dihapus setelah dikonfirmasi.
+// File mentah belum divalidasi, jadi tidak pernah ditaruh di storage publik.
+const (
+	IncomingFolder      = PhotoFolder + "/incoming"
+	VideoIncomingFolder = VideoFolder + "/incoming"
+)
+
+// incomingExtensions extension file mentah sesuai content_type yang sudah divalidasi,
+// extension dari nama file client tidak dipakai
+var incomingExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+	"video/mp4":  ".mp4",
+	"video/webm": ".webm",
+}
+
+type SignUploadRequest struct {
+	Filename    string `json:"filename" binding:"required,max=255"`
+	ContentType string `json:"content_type" binding:"required"`
+	Size        int64  `json:"size" binding:"required,gt=0"`
+}
+
+type ConfirmUploadRequest struct {
+	UploadID   uint   `json:"upload_id" binding:"required"`
+	PropertyID uint   `json:"property_id"` // Optional: langsung dibuatkan PropertyPhoto
+	Caption    string `json:"caption" binding:"max=500"`
+}
+
+// SignUpload membuat parameter upload langsung dari browser ke storage untuk foto dan video besar.
+// File mentah masuk ke storage private dan baru dipindah ke storage publik setelah
+// lolos validasi di ConfirmUpload, jadi client wajib memanggil ConfirmUpload dengan upload_id.
+func (h *UploadHandler) SignUpload(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	uploader, ok := h.private.(storage.DirectUploader)
+	if !ok {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Storage " + h.private.Name() + " tidak mendukung upload langsung"})
+		return
+	}
+
+	var req SignUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": err.Error()})
+		return
+	}
+	req.ContentType = strings.ToLower(strings.TrimSpace(req.ContentType))
+	folder, maxSize, maxSizeMB := IncomingFolder, int64(MaxFileSize), MaxFileSizeMB
+	if _, ok := AllowedVideoTypes[req.ContentType]; ok {
+		folder, maxSize, maxSizeMB = VideoIncomingFolder, MaxVideoSize, MaxVideoSizeMB
+	} else if !AllowedImageTypes[req.ContentType] {
+		respondValidationError(c, &ValidationError{
+			Code:    "INVALID_FILE_TYPE",
+			Message: "Tipe file tidak didukung (" + req.ContentType + "), gunakan: jpg, jpeg, png, gif, webp, mp4, webm",
+		})
+		return
+	}
+	if req.Size > maxSize {
+		respondValidationError(c, &ValidationError{
+			Code:    "FILE_TOO_LARGE",
+			Message: "Ukuran file terlalu besar, maksimal " + strconv.Itoa(maxSizeMB) + "MB",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	key := storage.NewKey(folder, strings.TrimSuffix(req.Filename, path.Ext(req.Filename))+incomingExtensions[req.ContentType])
+	target, err := uploader.PresignUpload(ctx, key, req.ContentType, maxSize, DirectUploadExpiry)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat signed upload", "details": err.Error()})
+		return
+	}
+
+	// URL publik hanya placeholder unik sampai dikonfirmasi, file mentah dicatat sebagai OriginalKey
+	// supaya GC menghapusnya dari storage private kalau upload tidak pernah dikonfirmasi
+	asset := models.MediaAsset{
+		UserID:       userID.(uint),
+		URL:          h.store.URL(key),
+		OriginalKey:  key,
+		OriginalName: req.Filename,
+		ContentType:  req.ContentType,
+		Size:         req.Size,
+		Status:       models.MediaStatusPending,
+	}
+	if err := h.assets.CreateAsset(&asset); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan data", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"upload_id": asset.ID,
+		"key":       key,
+		"upload":    target,
+	}})
+}
+
+// ConfirmUpload memverifikasi file hasil upload langsung, memprosesnya seperti UploadFile
+// (validasi isi, buang metadata, rendition) atau UploadVideo untuk video, dan optional membuat PropertyPhoto
+func (h *UploadHandler) ConfirmUpload(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req ConfirmUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": err.Error()})
+		return
+	}
+
+	asset, err := h.assets.GetUserAsset(req.UploadID, userID.(uint), models.MediaStatusPending)
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+
+	// Cek kepemilikan listing sebelum file diproses
+	if req.PropertyID > 0 && !h.checkListingOwner(c, req.PropertyID, userID.(uint)) {
+		return
+	}
+	if _, ok := AllowedVideoTypes[asset.ContentType]; ok {
+		h.confirmVideo(c, asset, &req)
+		return
+	}
+
+	ctx := c.Request.Context()
+	incomingKey := asset.OriginalKey
+	src, err := h.private.Open(ctx, incomingKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File belum diupload ke storage", "code": "UPLOAD_NOT_FOUND"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membaca file dari storage", "details": err.Error()})
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(src, MaxFileSize+1))
+	src.Close()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membaca file dari storage", "details": err.Error()})
+		return
+	}
+
+	// File mentah yang tidak lolos validasi langsung dibuang
+	verr := validateImageContent(data)
+	if int64(len(data)) > MaxFileSize {
+		verr = &ValidationError{Code: "FILE_TOO_LARGE", Message: "Ukuran file terlalu besar, maksimal " + strconv.Itoa(MaxFileSizeMB) + "MB"}
+	}
+	if verr != nil {
+		h.discardIncoming(c, asset)
+		respondValidationError(c, verr)
+		return
+	}
+
+	uploaded, duplicates, err := h.processAndStore(ctx, asset.OriginalName, data, asset, req.PropertyID)
+	if err != nil {
+		var validationErr *ValidationError
+		var duplicateErr *DuplicatePhotoError
+		if errors.As(err, &validationErr) || errors.As(err, &duplicateErr) {
+			h.discardIncoming(c, asset)
+		}
+		respondStoreError(c, err)
+		return
+	}
+
+	asset.Status = models.MediaStatusReady
+	if err := h.assets.SaveAsset(asset); err != nil {
+		h.cleanup(ctx, uploaded)
+		h.cleanupPrivate(ctx, asset.OriginalKey)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan data", "details": err.Error()})
+		return
+	}
+	h.cleanupPrivate(ctx, incomingKey)
+
+	response := uploadResponse(asset)
+	response["message"] = "File berhasil diupload"
+	addDuplicateWarning(response, duplicates)
+	h.respondConfirmed(c, response, asset, req, models.MediaKindPhoto)
+}
+
+// confirmVideo memproses video hasil upload langsung lewat pipeline UploadVideo (probe, buang metadata,
+// poster). Video disalin ke file sementara karena ffmpeg butuh path file.
+func (h *UploadHandler) confirmVideo(c *gin.Context, asset *models.MediaAsset, req *ConfirmUploadRequest) {
+	extendDeadlines(c, 0, VideoUploadTimeout)
+
+	ctx := c.Request.Context()
+	incomingKey := asset.OriginalKey
+	src, err := h.private.Open(ctx, incomingKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File belum diupload ke storage", "code": "UPLOAD_NOT_FOUND"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membaca file dari storage", "details": err.Error()})
+		return
+	}
+	tmpDir, err := os.MkdirTemp("", "video-confirm-*")
+	if err != nil {
+		src.Close()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyiapkan file sementara", "details": err.Error()})
+		return
+	}
+	defer os.RemoveAll(tmpDir)
+	rawPath := filepath.Join(tmpDir, "raw")
+	err = writeFile(rawPath, io.LimitReader(src, MaxVideoSize+1))
+	src.Close()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membaca file dari storage", "details": err.Error()})
+		return
+	}
+	if stat, err := os.Stat(rawPath); err != nil || stat.Size() > MaxVideoSize {
+		h.discardIncoming(c, asset)
+		respondValidationError(c, &ValidationError{
+			Code:    "FILE_TOO_LARGE",
+			Message: "Ukuran video terlalu besar, maksimal " + strconv.Itoa(MaxVideoSizeMB) + "MB",
+		})
+		return
+	}
+
+	uploaded, err := h.processVideo(ctx, asset.OriginalName, rawPath, asset)
+	if err != nil {
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			h.discardIncoming(c, asset)
+		}
+		respondVideoError(c, err)
+		return
+	}
+
+	// Video tidak punya foto asli terpisah, OriginalKey hanya dipakai selama pending
+	asset.OriginalKey = ""
+	asset.Status = models.MediaStatusReady
+	if err := h.assets.SaveAsset(asset); err != nil {
+		h.cleanup(ctx, uploaded)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan data", "details": err.Error()})
+		return
+	}
+	h.cleanupPrivate(ctx, incomingKey)
+
+	response := uploadResponse(asset)
+	response["message"] = "Video berhasil diupload"
+	h.respondConfirmed(c, response, asset, *req, models.MediaKindVideo)
+}
+
+// respondConfirmed mengirim hasil ConfirmUpload, kalau property_id diisi media langsung dipasang ke listing
+func (h *UploadHandler) respondConfirmed(c *gin.Context, response gin.H, asset *models.MediaAsset, req ConfirmUploadRequest, kind string) {
+	if req.PropertyID == 0 {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	photo := models.PropertyPhoto{PropertyID: req.PropertyID, Kind: kind, PhotoPath: asset.URL, Caption: req.Caption}
+	err := h.photos.AddPhoto(&photo)
+	var limitErr *database.MediaLimitError
+	if errors.As(err, &limitErr) {
+		response["error"] = err.Error()
+		response["code"] = "MEDIA_LIMIT_REACHED"
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	if err == database.ErrPhotoLimitReached {
+		response["error"] = err.Error()
+		response["code"] = "PHOTO_LIMIT_REACHED"
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	if err == database.ErrDuplicatePhoto {
+		response["error"] = err.Error()
+		response["code"] = "DUPLICATE_PHOTO"
+		c.JSON(http.StatusConflict, response)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan foto", "details": err.Error()})
+		return
+	}
+	response["photo"] = photo
+	c.JSON(http.StatusCreated, response)
+}
+
+// ReceiveDirectUpload menerima upload langsung untuk storage private driver local (PUT dengan signature dari SignUpload)
+func (h *UploadHandler) ReceiveDirectUpload(c *gin.Context) {
+	local, ok := h.private.(*storage.LocalStorage)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		return
+	}
+
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if err := local.VerifyUploadSignature(key, c.Query("expires"), c.Query("signature")); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Batas ukuran mengikuti folder dari SignUpload, video boleh lebih besar dan lebih lama diterima
+	maxSize, maxSizeMB := int64(MaxFileSize), MaxFileSizeMB
+	if strings.HasPrefix(key, VideoIncomingFolder+"/") {
+		maxSize, maxSizeMB = MaxVideoSize, MaxVideoSizeMB
+		extendDeadlines(c, VideoUploadTimeout, VideoUploadTimeout)
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxSize)
+	if _, err := local.Put(c.Request.Context(), key, body, c.Request.ContentLength, c.ContentType()); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			respondValidationError(c, &ValidationError{
+				Code:    "FILE_TOO_LARGE",
+				Message: "Ukuran file terlalu besar, maksimal " + strconv.Itoa(maxSizeMB) + "MB",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal upload ke storage", "details": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// discardIncoming menghapus file mentah dan catatan pending yang gagal validasi
+func (h *UploadHandler) discardIncoming(c *gin.Context, asset *models.MediaAsset) {
+	h.cleanupPrivate(c.Request.Context(), asset.OriginalKey)
+	if err := h.assets.DeleteAsset(asset); err != nil {
+		fmt.Printf("⚠️  Gagal hapus catatan upload %d: %v\n", asset.ID, err)
+	}
+}
@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"project-zero/internal/models"
+	"project-zero/pkg/imaging"
+	"project-zero/pkg/storage"
+	"project-zero/pkg/video"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/gin-gonic/gin"
+)
+
+// Batas upload video listing
+const (
+	MaxVideoSize      = 200 * 1024 * 1024 // 200MB
+	MaxVideoSizeMB    = 200
+	MaxVideoDuration  = 3 * time.Minute
+	MaxVideoDimension = 3840 // 4K UHD, sisi terpanjang
+
+	VideoProcessTimeout = 2 * time.Minute // Batas waktu probe, buang metadata dan ambil poster
+
+	// Batas waktu terima body video. Timeout server (90 detik) terlalu pendek untuk 200MB,
+	// jadi deadline koneksi diperpanjang khusus endpoint ini, sejalan dengan proxy_read_timeout nginx (180 detik).
+	VideoUploadTimeout = 3 * time.Minute
+)
+
+// VideoFolder folder storage untuk video properti
+const VideoFolder = "property-videos"
+
+// AllowedVideoTypes tipe MIME video yang diterima, dideteksi dari isi file. Nilainya format container untuk ffmpeg.
+var AllowedVideoTypes = map[string]string{
+	"video/mp4":  "mp4",
+	"video/webm": "webm",
+}
+
+// UploadVideo menghandle upload video listing (MP4/WebM). Metadata video dibuang tanpa encode ulang,
+// lalu poster frame diambil di server dan dibuatkan rendition seperti foto (termasuk watermark
+// organisasi). Video-nya sendiri tidak diberi watermark.
+func (h *UploadHandler) UploadVideo(c *gin.Context) {
+	// Response baru dikirim setelah body selesai diterima dan video diproses
+	extendDeadlines(c, VideoUploadTimeout, VideoUploadTimeout+VideoProcessTimeout)
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal ambil file"})
+		return
+	}
+	if file.Size > MaxVideoSize {
+		respondValidationError(c, &ValidationError{
+			Code:    "FILE_TOO_LARGE",
+			Message: "Ukuran video terlalu besar, maksimal " + strconv.Itoa(MaxVideoSizeMB) + "MB",
+		})
+		return
+	}
+
+	// ffprobe/ffmpeg butuh path file, jadi video ditulis ke file sementara
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal membuka file"})
+		return
+	}
+	defer src.Close()
+	tmpDir, err := os.MkdirTemp("", "video-upload-*")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyiapkan file sementara", "details": err.Error()})
+		return
+	}
+	defer os.RemoveAll(tmpDir)
+	rawPath := filepath.Join(tmpDir, "raw")
+	if err := writeFile(rawPath, src); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal membaca file"})
+		return
+	}
+
+	asset := models.MediaAsset{
+		Status:       models.MediaStatusReady,
+		OriginalName: file.Filename,
+	}
+	if userID, exists := c.Get("userID"); exists {
+		asset.UserID = userID.(uint)
+	}
+	ctx := c.Request.Context()
+	uploaded, err := h.processVideo(ctx, file.Filename, rawPath, &asset)
+	if err != nil {
+		respondVideoError(c, err)
+		return
+	}
+
+	if err := h.assets.CreateAsset(&asset); err != nil {
+		h.cleanup(ctx, uploaded)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal menyimpan data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response := uploadResponse(&asset)
+	response["message"] = "Video berhasil diupload"
+	c.JSON(http.StatusOK, response)
+}
+
+// processVideo memvalidasi video mentah di rawPath (tipe dari isi file, durasi, resolusi), membuang
+// metadata, mengambil poster frame lalu menyimpan semuanya lewat storeVideo. File hasil ditulis di
+// folder yang sama dengan rawPath. Error validasi berupa *ValidationError.
+func (h *UploadHandler) processVideo(ctx context.Context, filename, rawPath string, asset *models.MediaAsset) ([]string, error) {
+	mime, err := mimetype.DetectFile(rawPath)
+	if err != nil {
+		return nil, &ValidationError{Code: "INVALID_VIDEO", Message: "Gagal membaca file"}
+	}
+	format, ok := AllowedVideoTypes[mime.String()]
+	if !ok {
+		return nil, &ValidationError{
+			Code:    "INVALID_FILE_TYPE",
+			Message: "Tipe file tidak didukung (" + mime.String() + "), gunakan: mp4, webm",
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, VideoProcessTimeout)
+	defer cancel()
+
+	info, err := h.video.Probe(ctx, rawPath, format)
+	if err != nil {
+		return nil, videoToolError(err)
+	}
+	if err := validateVideoInfo(info); err != nil {
+		return nil, err
+	}
+
+	cleanPath := filepath.Join(filepath.Dir(rawPath), "clean."+format)
+	if err := h.video.StripMetadata(ctx, rawPath, cleanPath, format); err != nil {
+		return nil, videoToolError(err)
+	}
+	poster, err := h.video.PosterFrame(ctx, cleanPath, format, video.PosterTime(info.Duration))
+	if err != nil {
+		return nil, videoToolError(err)
+	}
+
+	asset.ContentType = mime.String()
+	asset.Width = info.Width
+	asset.Height = info.Height
+	asset.Duration = info.Duration
+	asset.MetadataStripped = true
+	return h.storeVideo(ctx, filename, cleanPath, poster, asset)
+}
+
+// extendDeadlines memperpanjang deadline baca/tulis koneksi untuk request video, timeout server
+// (90 detik) terlalu pendek untuk body 200MB dan proses ffmpeg. Durasi 0 berarti tidak diubah.
+func extendDeadlines(c *gin.Context, read, write time.Duration) {
+	rc := http.NewResponseController(c.Writer)
+	now := time.Now()
+	if read > 0 {
+		if err := rc.SetReadDeadline(now.Add(read)); err != nil {
+			fmt.Printf("⚠️  Gagal set read deadline %s: %v\n", c.FullPath(), err)
+		}
+	}
+	if write > 0 {
+		if err := rc.SetWriteDeadline(now.Add(write)); err != nil {
+			fmt.Printf("⚠️  Gagal set write deadline %s: %v\n", c.FullPath(), err)
+		}
+	}
+}
+
+// storeVideo menyimpan video dan poster frame beserta rendition-nya ke storage, hasilnya diisi
+// ke asset tanpa disimpan ke database. Key yang sudah diupload dikembalikan untuk cleanup.
+func (h *UploadHandler) storeVideo(ctx context.Context, filename, path string, poster []byte, asset *models.MediaAsset) ([]string, error) {
+	watermark, err := h.watermarkFor(ctx, asset.UserID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := h.processor.Process(poster, nil, watermark)
+	if err != nil {
+		return nil, fmt.Errorf("Gagal memproses poster video: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	ext := "." + AllowedVideoTypes[asset.ContentType]
+	key := storage.NewKey(VideoFolder, strings.TrimSuffix(filename, filepath.Ext(filename))+ext)
+	videoURL, err := h.store.Put(ctx, key, f, stat.Size(), asset.ContentType)
+	if err != nil {
+		return nil, fmt.Errorf("Gagal upload ke storage: %v", err)
+	}
+
+	// Poster ukuran asli (ber-watermark kalau ada) disimpan sebagai rendition "poster"
+	posterOut := result.Original
+	if result.Public != nil {
+		posterOut = *result.Public
+	}
+	posterOut.Name = models.RenditionPoster
+
+	uploaded := []string{key}
+	renditions := make(models.ImageRenditions, 0, len(result.Renditions)+1)
+	for _, out := range append([]imaging.Output{posterOut}, result.Renditions...) {
+		renditionKey := renditionKey(key, out)
+		url, err := h.store.Put(ctx, renditionKey, bytes.NewReader(out.Data), int64(len(out.Data)), out.ContentType)
+		if err != nil {
+			h.cleanup(ctx, uploaded)
+			return nil, fmt.Errorf("Gagal upload poster ke storage: %v", err)
+		}
+		uploaded = append(uploaded, renditionKey)
+		renditions = append(renditions, models.ImageRendition{Name: out.Name, URL: url, Width: out.Width, Height: out.Height})
+	}
+
+	asset.URL = videoURL
+	asset.StorageKey = key
+	asset.Size = stat.Size()
+	asset.Renditions = renditions
+	return uploaded, nil
+}
+
+// validateVideoInfo memastikan durasi dan resolusi video dalam batas
+func validateVideoInfo(info *video.Info) error {
+	if info.Duration <= 0 || info.Width <= 0 || info.Height <= 0 {
+		return &ValidationError{Code: "INVALID_VIDEO", Message: "File video rusak atau tidak bisa dibaca"}
+	}
+	if info.Duration > MaxVideoDuration.Seconds() {
+		return &ValidationError{
+			Code:    "VIDEO_TOO_LONG",
+			Message: fmt.Sprintf("Durasi video %.0f detik terlalu panjang, maksimal %.0f detik", info.Duration, MaxVideoDuration.Seconds()),
+		}
+	}
+	if info.Width > MaxVideoDimension || info.Height > MaxVideoDimension {
+		return &ValidationError{
+			Code:    "VIDEO_DIMENSIONS_TOO_LARGE",
+			Message: fmt.Sprintf("Resolusi video %dx%d terlalu besar, maksimal %d pixel per sisi", info.Width, info.Height, MaxVideoDimension),
+		}
+	}
+	return nil
+}
+
+// videoToolError: ffmpeg tidak terpasang dikembalikan apa adanya, selain itu file video dianggap rusak
+func videoToolError(err error) error {
+	if errors.Is(err, video.ErrUnavailable) {
+		return err
+	}
+	return &ValidationError{Code: "INVALID_VIDEO", Message: "Video tidak bisa diproses: " + err.Error()}
+}
+
+// respondVideoError: ffmpeg tidak terpasang jadi 503, error validasi 400, selain itu gagal simpan ke storage
+func respondVideoError(c *gin.Context, err error) {
+	if errors.Is(err, video.ErrUnavailable) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Upload video belum tersedia", "details": err.Error()})
+		return
+	}
+	respondStoreError(c, err)
+}
+
+func writeFile(path string, r io.Reader) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,519 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"project-zero/internal/models"
+	"project-zero/pkg/database"
+	"project-zero/pkg/ical"
+	"project-zero/pkg/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Batas durasi viewing dan slot
+const (
+	DefaultViewingDuration = 30 * time.Minute
+	MinViewingDuration     = 15 * time.Minute
+	MaxViewingDuration     = 2 * time.Hour
+	MaxSlotDuration        = 12 * time.Hour
+	MinViewingNotice       = time.Hour // Viewing paling cepat 1 jam dari sekarang
+)
+
+// CalendarFeedHistory viewing yang sudah lewat tetap muncul di feed selama jangka ini
+const CalendarFeedHistory = 30 * 24 * time.Hour
+
+type ViewingHandler struct {
+	repo   *database.ViewingRepository
+	photos *database.PropertyPhotoRepository
+}
+
+// NewViewingHandler membuat instance baru ViewingHandler
+func NewViewingHandler(repo *database.ViewingRepository, photos *database.PropertyPhotoRepository) *ViewingHandler {
+	return &ViewingHandler{repo: repo, photos: photos}
+}
+
+// CreateSlotRequest slot ketersediaan pemilik listing
+type CreateSlotRequest struct {
+	StartsAt time.Time `json:"starts_at" binding:"required"` // RFC3339
+	EndsAt   time.Time `json:"ends_at" binding:"required"`
+	Notes    string    `json:"notes" binding:"max=500"`
+}
+
+// ViewingTimeRequest waktu viewing yang diusulkan, durasi default 30 menit
+type ViewingTimeRequest struct {
+	StartsAt        time.Time `json:"starts_at" binding:"required"` // RFC3339
+	DurationMinutes int       `json:"duration_minutes" binding:"omitempty,min=15,max=120"`
+}
+
+// RequestViewingRequest booking viewing oleh pembeli
+type RequestViewingRequest struct {
+	ViewingTimeRequest
+	Message string `json:"message" binding:"max=1000"`
+}
+
+// ViewingReasonRequest alasan decline/cancel
+type ViewingReasonRequest struct {
+	Reason string `json:"reason" binding:"max=1000"`
+}
+
+// CreateSlot menambahkan slot ketersediaan viewing ke listing (pemilik listing)
+func (h *ViewingHandler) CreateSlot(c *gin.Context) {
+	propertyID, ok := ownedPropertyID(c, h.photos, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var req CreateSlotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": err.Error()})
+		return
+	}
+	switch {
+	case !req.EndsAt.After(req.StartsAt):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": "ends_at harus setelah starts_at"})
+		return
+	case req.EndsAt.Sub(req.StartsAt) < MinViewingDuration:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": "Slot minimal 15 menit"})
+		return
+	case req.EndsAt.Sub(req.StartsAt) > MaxSlotDuration:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": "Slot maksimal 12 jam, buat beberapa slot untuk hari yang berbeda"})
+		return
+	case !req.EndsAt.After(time.Now()):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": "Slot sudah lewat"})
+		return
+	}
+
+	slot := models.ViewingSlot{
+		PropertyID: propertyID,
+		OwnerID:    currentUserID(c),
+		StartsAt:   req.StartsAt.UTC(),
+		EndsAt:     req.EndsAt.UTC(),
+		Notes:      strings.TrimSpace(req.Notes),
+	}
+	if err := h.repo.CreateSlot(&slot); err != nil {
+		if err == database.ErrSlotOverlap {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "SLOT_OVERLAP"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan data", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": slot})
+}
+
+// GetSlots mengambil slot viewing listing yang belum lewat
+func (h *ViewingHandler) GetSlots(c *gin.Context) {
+	propertyID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return
+	}
+
+	slots, err := h.repo.GetUpcomingSlots(uint(propertyID), time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": slots})
+}
+
+// DeleteSlot menghapus slot viewing (pemilik listing). Viewing yang sudah dibooking tidak ikut batal.
+func (h *ViewingHandler) DeleteSlot(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return
+	}
+
+	slot, err := h.repo.GetSlotByID(uint(id))
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+	if slot.OwnerID != currentUserID(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Bukan listing milik kamu"})
+		return
+	}
+
+	if err := h.repo.DeleteSlot(slot); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus data", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Slot berhasil dihapus"})
+}
+
+// RequestViewing booking viewing oleh pembeli di dalam slot yang disediakan pemilik listing
+func (h *ViewingHandler) RequestViewing(c *gin.Context) {
+	propertyID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return
+	}
+
+	var req RequestViewingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": err.Error()})
+		return
+	}
+	startsAt, endsAt, ok := viewingTime(c, &req.ViewingTimeRequest)
+	if !ok {
+		return
+	}
+
+	viewing := models.Viewing{
+		PropertyID: uint(propertyID),
+		BuyerID:    currentUserID(c),
+		StartsAt:   startsAt,
+		EndsAt:     endsAt,
+		Message:    strings.TrimSpace(req.Message),
+	}
+	if err := h.repo.RequestViewing(&viewing); err != nil {
+		respondViewingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": viewing})
+}
+
+// GetViewings mengambil viewing user yang login. Filter ?role=buyer|owner, ?status=,
+// pagination ?page=&limit=
+func (h *ViewingHandler) GetViewings(c *gin.Context) {
+	role := c.Query("role")
+	if role != "" && role != "buyer" && role != "owner" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role, gunakan buyer atau owner"})
+		return
+	}
+	status := c.Query("status")
+	switch status {
+	case "", models.ViewingRequested, models.ViewingConfirmed, models.ViewingDeclined, models.ViewingCancelled:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+	page, limit := utils.ParsePagination(c)
+
+	viewings, total, err := h.repo.GetUserViewings(currentUserID(c), role, status, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": viewings,
+		"pagination": utils.PaginationMetadata{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: utils.CalculateTotalPages(total, limit),
+		},
+	})
+}
+
+// GetViewing mengambil detail satu viewing
+func (h *ViewingHandler) GetViewing(c *gin.Context) {
+	viewing, ok := h.participantViewing(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": viewing})
+}
+
+// AcceptViewing menerima waktu yang diusulkan pihak lain
+func (h *ViewingHandler) AcceptViewing(c *gin.Context) {
+	viewing, ok := h.participantViewing(c)
+	if !ok {
+		return
+	}
+	if err := h.repo.AcceptViewing(viewing, currentUserID(c)); err != nil {
+		respondViewingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": viewing})
+}
+
+// DeclineViewing menolak waktu yang diusulkan pihak lain
+func (h *ViewingHandler) DeclineViewing(c *gin.Context) {
+	viewing, ok := h.participantViewing(c)
+	if !ok {
+		return
+	}
+	reason, ok := bindViewingReason(c)
+	if !ok {
+		return
+	}
+	if err := h.repo.DeclineViewing(viewing, currentUserID(c), reason); err != nil {
+		respondViewingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": viewing})
+}
+
+// RescheduleViewing mengusulkan waktu baru, pihak lain harus accept lagi
+func (h *ViewingHandler) RescheduleViewing(c *gin.Context) {
+	viewing, ok := h.participantViewing(c)
+	if !ok {
+		return
+	}
+
+	var req ViewingTimeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": err.Error()})
+		return
+	}
+	startsAt, endsAt, ok := viewingTime(c, &req)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.RescheduleViewing(viewing, currentUserID(c), startsAt, endsAt); err != nil {
+		respondViewingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": viewing})
+}
+
+// CancelViewing membatalkan viewing, bisa oleh pembeli maupun pemilik listing
+func (h *ViewingHandler) CancelViewing(c *gin.Context) {
+	viewing, ok := h.participantViewing(c)
+	if !ok {
+		return
+	}
+	reason, ok := bindViewingReason(c)
+	if !ok {
+		return
+	}
+	if err := h.repo.CancelViewing(viewing, reason); err != nil {
+		respondViewingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": viewing})
+}
+
+// GetViewingInvite mengunduh undangan .ics untuk satu viewing. Viewing yang sudah
+// ditolak/dibatalkan dikirim sebagai METHOD:CANCEL supaya event di kalender ikut terhapus.
+func (h *ViewingHandler) GetViewingInvite(c *gin.Context) {
+	viewing, ok := h.participantViewing(c)
+	if !ok {
+		return
+	}
+	users, err := h.repo.GetUsers(viewing.OwnerID, viewing.BuyerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+
+	method := ical.MethodRequest
+	if !viewing.IsOpen() {
+		method = ical.MethodCancel
+	}
+	calendar := ical.Calendar{Method: method, Events: []ical.Event{viewingEvent(viewing, users)}}
+	writeCalendar(c, &calendar, fmt.Sprintf("viewing-%d.ics", viewing.ID))
+}
+
+// RotateCalendarFeed membuat (atau mengganti) link feed iCalendar viewing confirmed milik user.
+// Link lama langsung tidak berlaku.
+func (h *ViewingHandler) RotateCalendarFeed(c *gin.Context) {
+	feed, err := h.repo.RotateCalendarFeed(currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan data", "details": err.Error()})
+		return
+	}
+
+	feedURL := fmt.Sprintf("%s/calendar/%s/viewings.ics", publicBaseURL(), feed.Token)
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"url":        feedURL,
+		"webcal_url": "webcal://" + strings.TrimPrefix(strings.TrimPrefix(feedURL, "https://"), "http://"),
+		"created_at": feed.CreatedAt,
+	}})
+}
+
+// DeleteCalendarFeed mematikan link feed iCalendar user
+func (h *ViewingHandler) DeleteCalendarFeed(c *gin.Context) {
+	if err := h.repo.DeleteCalendarFeed(currentUserID(c)); err != nil {
+		if err == database.ErrCalendarFeedAbsent {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus data", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Feed kalender berhasil dimatikan"})
+}
+
+// GetCalendarFeed feed iCalendar viewing confirmed (PUBLIC, diakses aplikasi kalender lewat token)
+func (h *ViewingHandler) GetCalendarFeed(c *gin.Context) {
+	feed, err := h.repo.FindCalendarFeed(c.Param("token"))
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+
+	viewings, err := h.repo.GetConfirmedViewings(feed.UserID, time.Now().Add(-CalendarFeedHistory))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+	userIDs := []uint{feed.UserID}
+	for _, viewing := range viewings {
+		userIDs = append(userIDs, viewing.OwnerID, viewing.BuyerID)
+	}
+	users, err := h.repo.GetUsers(userIDs...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return
+	}
+
+	calendar := ical.Calendar{Name: "Jadwal Viewing", Method: ical.MethodPublish}
+	for i := range viewings {
+		calendar.Events = append(calendar.Events, viewingEvent(&viewings[i], users))
+	}
+	c.Header("Cache-Control", "private, max-age=300")
+	writeCalendar(c, &calendar, "viewings.ics")
+}
+
+// participantViewing mengambil viewing dari param :id. Viewing di mana user bukan pembeli
+// maupun pemilik listing dianggap tidak ada.
+func (h *ViewingHandler) participantViewing(c *gin.Context) (*models.Viewing, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return nil, false
+	}
+
+	viewing, err := h.repo.GetViewingByID(uint(id))
+	if err == gorm.ErrRecordNotFound || (err == nil && !viewing.HasParticipant(currentUserID(c))) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data", "details": err.Error()})
+		return nil, false
+	}
+	return viewing, true
+}
+
+// viewingTime menghitung rentang viewing dari request dan memastikan tidak terlalu mepet
+func viewingTime(c *gin.Context, req *ViewingTimeRequest) (time.Time, time.Time, bool) {
+	duration := DefaultViewingDuration
+	if req.DurationMinutes > 0 {
+		duration = time.Duration(req.DurationMinutes) * time.Minute
+	}
+	startsAt := req.StartsAt.UTC()
+	if startsAt.Before(time.Now().Add(MinViewingNotice)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": "Viewing paling cepat 1 jam dari sekarang"})
+		return time.Time{}, time.Time{}, false
+	}
+	return startsAt, startsAt.Add(duration), true
+}
+
+func bindViewingReason(c *gin.Context) (string, bool) {
+	var req ViewingReasonRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": err.Error()})
+			return "", false
+		}
+	}
+	return strings.TrimSpace(req.Reason), true
+}
+
+// respondViewingError mengirim response sesuai error dari ViewingRepository
+func respondViewingError(c *gin.Context, err error) {
+	var conflict *database.ViewingConflictError
+	switch {
+	case errors.As(err, &conflict):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+			"code":  "VIEWING_CONFLICT",
+			"conflict": gin.H{
+				"starts_at": conflict.Conflict.StartsAt,
+				"ends_at":   conflict.Conflict.EndsAt,
+			},
+		})
+	case err == gorm.ErrRecordNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data gak ketemu"})
+	case err == database.ErrOutsideSlot:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "OUTSIDE_SLOT"})
+	case err == database.ErrOwnListingViewing:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validasi gagal", "details": err.Error()})
+	case err == database.ErrViewingNotPending, err == database.ErrViewingClosed:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan data", "details": err.Error()})
+	}
+}
+
+// viewingEvent membuat event iCalendar dari viewing. Pemilik listing jadi organizer, pembeli attendee.
+func viewingEvent(viewing *models.Viewing, users map[uint]models.User) ical.Event {
+	status := ical.StatusTentative
+	switch viewing.Status {
+	case models.ViewingConfirmed:
+		status = ical.StatusConfirmed
+	case models.ViewingDeclined, models.ViewingCancelled:
+		status = ical.StatusCancelled
+	}
+
+	title := viewing.PropertyTitle
+	if title == "" {
+		title = fmt.Sprintf("Listing #%d", viewing.PropertyID)
+	}
+	description := viewing.Message
+	if viewing.Reason != "" {
+		description = strings.TrimSpace(description + "\n\nAlasan: " + viewing.Reason)
+	}
+
+	event := ical.Event{
+		UID:         fmt.Sprintf("viewing-%d@%s", viewing.ID, calendarHost()),
+		Sequence:    viewing.Sequence,
+		Start:       viewing.StartsAt,
+		End:         viewing.EndsAt,
+		Stamp:       viewing.UpdatedAt,
+		Summary:     "Viewing: " + title,
+		Description: description,
+		Location:    viewing.PropertyAddress,
+		URL:         publicListingURL(viewing.PropertyID),
+		Status:      status,
+	}
+	if owner, ok := users[viewing.OwnerID]; ok {
+		event.Organizer = &ical.Attendee{Name: owner.Name, Email: owner.Email}
+	}
+	if buyer, ok := users[viewing.BuyerID]; ok {
+		event.Attendees = []ical.Attendee{{Name: buyer.Name, Email: buyer.Email}}
+	}
+	return event
+}
+
+// calendarHost domain untuk UID event, diambil dari PUBLIC_BASE_URL
+func calendarHost() string {
+	if u, err := url.Parse(publicBaseURL()); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	return "localhost"
+}
+
+func writeCalendar(c *gin.Context, calendar *ical.Calendar, filename string) {
+	var buf bytes.Buffer
+	if err := calendar.Write(&buf); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat kalender", "details": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, filename))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
@@ -0,0 +1,161 @@
+// Package ical menulis file iCalendar (RFC 5545) untuk undangan dan feed jadwal viewing,
+// bisa dibuka atau di-subscribe dari Google Calendar, Apple Calendar dan Outlook.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Method iTIP untuk file undangan
+const (
+	MethodPublish = "PUBLISH" // Feed langganan
+	MethodRequest = "REQUEST" // Undangan event
+	MethodCancel  = "CANCEL"  // Pembatalan event
+)
+
+// Status event
+const (
+	StatusTentative = "TENTATIVE"
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+// ProductID identitas aplikasi pembuat kalender
+const ProductID = "-//Project Zero//Viewing Schedule//ID"
+
+// maxLineOctets panjang baris maksimal sebelum dilipat (RFC 5545 bagian 3.1)
+const maxLineOctets = 75
+
+// Attendee peserta event
+type Attendee struct {
+	Name  string
+	Email string
+}
+
+// Event satu jadwal di kalender
+type Event struct {
+	UID         string // Harus tetap sama untuk event yang sama supaya update/cancel menimpa event lama
+	Sequence    int    // Naik setiap kali event diubah
+	Start       time.Time
+	End         time.Time
+	Stamp       time.Time
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Status      string
+	Organizer   *Attendee
+	Attendees   []Attendee
+}
+
+// Calendar kumpulan event dalam satu file .ics
+type Calendar struct {
+	Name   string // X-WR-CALNAME, nama kalender saat di-subscribe
+	Method string
+	Events []Event
+}
+
+// Write menulis kalender dalam format iCalendar (baris CRLF, dilipat per 75 octet)
+func (c *Calendar) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", ProductID)
+	line("CALSCALE", "GREGORIAN")
+	if c.Method != "" {
+		line("METHOD", c.Method)
+	}
+	if c.Name != "" {
+		line("X-WR-CALNAME", escapeText(c.Name))
+	}
+	for _, e := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("SEQUENCE", fmt.Sprint(e.Sequence))
+		line("DTSTAMP", formatTime(e.Stamp))
+		line("DTSTART", formatTime(e.Start))
+		line("DTEND", formatTime(e.End))
+		line("SUMMARY", escapeText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escapeText(e.Description))
+		}
+		if e.Location != "" {
+			line("LOCATION", escapeText(e.Location))
+		}
+		if e.URL != "" {
+			line("URL", e.URL)
+		}
+		if e.Status != "" {
+			line("STATUS", e.Status)
+		}
+		if e.Organizer != nil {
+			writeFolded(bw, "ORGANIZER"+nameParam(e.Organizer.Name)+":mailto:"+e.Organizer.Email)
+		}
+		for _, a := range e.Attendees {
+			writeFolded(bw, "ATTENDEE;ROLE=REQ-PARTICIPANT"+nameParam(a.Name)+":mailto:"+a.Email)
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// formatTime waktu UTC format iCalendar, contoh 20260315T030000Z
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escapeText escape nilai TEXT: backslash, titik koma, koma dan baris baru
+func escapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+// nameParam parameter CN, tanda kutip dan karakter kontrol dibuang karena tidak boleh ada di nilai parameter
+func nameParam(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '"' || r < 0x20 {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" {
+		return ""
+	}
+	return `;CN="` + name + `"`
+}
+
+// writeFolded menulis satu content line, dilipat per 75 octet tanpa memotong karakter UTF-8
+func writeFolded(w *bufio.Writer, s string) {
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8Start(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = maxLineOctets - 1 // Baris lanjutan diawali satu spasi
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
+
+func utf8Start(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,196 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+	"strings"
+)
+
+// Tag EXIF IFD0 bertipe teks yang boleh dipertahankan lewat allowlist.
+// Tag GPS dan maker notes sengaja tidak ada di sini sehingga selalu dibuang.
+var exifTextTags = map[string]uint16{
+	"ImageDescription": 0x010E,
+	"Make":             0x010F,
+	"Model":            0x0110,
+	"Software":         0x0131,
+	"DateTime":         0x0132,
+	"Artist":           0x013B,
+	"Copyright":        0x8298,
+}
+
+const (
+	exifTagOrientation = 0x0112
+	exifTagGPSIFD      = 0x8825
+	exifTypeASCII      = 2
+)
+
+// AllowedMetadataTags daftar nama tag yang boleh dimasukkan ke allowlist
+func AllowedMetadataTags() []string {
+	names := make([]string, 0, len(exifTextTags))
+	for name := range exifTextTags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsAllowedMetadataTag mengecek apakah nama tag bisa dipertahankan
+func IsAllowedMetadataTag(name string) bool {
+	_, ok := exifTextTags[name]
+	return ok
+}
+
+type jpegSegment struct {
+	marker  byte
+	payload []byte
+}
+
+// jpegSegments membaca segment header JPEG sampai SOS (sebelum data gambar)
+func jpegSegments(data []byte) []jpegSegment {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil
+	}
+
+	var segments []jpegSegment
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			break
+		}
+		marker := data[pos+1]
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		if length < 2 || pos+2+length > len(data) {
+			break
+		}
+		segments = append(segments, jpegSegment{marker: marker, payload: data[pos+4 : pos+2+length]})
+		pos += 2 + length
+	}
+	return segments
+}
+
+func isExifSegment(seg jpegSegment) bool {
+	return seg.marker == 0xE1 && len(seg.payload) > 6 && string(seg.payload[:6]) == "Exif\x00\x00"
+}
+
+// exifInfo ringkasan isi EXIF yang dibutuhkan pipeline
+type exifInfo struct {
+	orientation int
+	hasGPS      bool
+	text        map[string]string // tag teks IFD0 berdasarkan nama
+}
+
+// parseExif membaca IFD0 dari header TIFF EXIF
+func parseExif(tiff []byte) exifInfo {
+	info := exifInfo{orientation: 1, text: map[string]string{}}
+	if len(tiff) < 8 {
+		return info
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return info
+	}
+
+	names := make(map[uint16]string, len(exifTextTags))
+	for name, tag := range exifTextTags {
+		names[tag] = name
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd+2 > len(tiff) {
+		return info
+	}
+	count := int(order.Uint16(tiff[ifd : ifd+2]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		tag := order.Uint16(tiff[entry : entry+2])
+		typ := order.Uint16(tiff[entry+2 : entry+4])
+		n := int(order.Uint32(tiff[entry+4 : entry+8]))
+
+		switch {
+		case tag == exifTagOrientation:
+			if o := int(order.Uint16(tiff[entry+8 : entry+10])); o >= 1 && o <= 8 {
+				info.orientation = o
+			}
+		case tag == exifTagGPSIFD:
+			info.hasGPS = true
+		case names[tag] != "" && typ == exifTypeASCII && n > 0:
+			value := tiff[entry+8 : entry+12]
+			if n > 4 {
+				offset := int(order.Uint32(tiff[entry+8 : entry+12]))
+				if offset < 0 || offset+n > len(tiff) {
+					continue
+				}
+				value = tiff[offset : offset+n]
+			} else {
+				value = value[:n]
+			}
+			if text := strings.TrimSpace(string(bytes.TrimRight(value, "\x00"))); text != "" {
+				info.text[names[tag]] = text
+			}
+		}
+	}
+	return info
+}
+
+// buildExifSegment membuat segment APP1 EXIF minimal yang hanya berisi tag teks tertentu
+func buildExifSegment(tags map[string]string) []byte {
+	type entry struct {
+		tag   uint16
+		value []byte
+	}
+	var entries []entry
+	for name, value := range tags {
+		if tag, ok := exifTextTags[name]; ok {
+			entries = append(entries, entry{tag: tag, value: append([]byte(value), 0)})
+		}
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].tag < entries[j].tag })
+
+	order := binary.BigEndian
+	ifdSize := 2 + len(entries)*12 + 4
+	dataOffset := 8 + ifdSize
+
+	var tiff, values bytes.Buffer
+	tiff.WriteString("MM")
+	binary.Write(&tiff, order, uint16(42))
+	binary.Write(&tiff, order, uint32(8))
+	binary.Write(&tiff, order, uint16(len(entries)))
+	for _, e := range entries {
+		binary.Write(&tiff, order, e.tag)
+		binary.Write(&tiff, order, uint16(exifTypeASCII))
+		binary.Write(&tiff, order, uint32(len(e.value)))
+		if len(e.value) <= 4 {
+			inline := make([]byte, 4)
+			copy(inline, e.value)
+			tiff.Write(inline)
+			continue
+		}
+		binary.Write(&tiff, order, uint32(dataOffset+values.Len()))
+		values.Write(e.value)
+	}
+	binary.Write(&tiff, order, uint32(0)) // tidak ada IFD berikutnya
+	tiff.Write(values.Bytes())
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	if len(payload)+2 > 0xFFFF {
+		return nil
+	}
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
@@ -0,0 +1,82 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// CheckGIFFrames menghitung frame GIF dari struktur block-nya tanpa decode LZW, lalu menolak
+// animasi yang lebih dari MaxGIFFrames frame atau total pixel semua frame-nya melebihi MaxImagePixels.
+// Header gambar hanya memuat ukuran layar, jadi GIF kecil dengan ribuan frame lolos dari cek dimensi biasa.
+func CheckGIFFrames(data []byte) error {
+	if len(data) < 13 || string(data[:3]) != "GIF" {
+		return errors.New("File GIF tidak valid")
+	}
+	pos := 13
+	if flags := data[10]; flags&0x80 != 0 {
+		pos += 3 << ((flags & 0x07) + 1)
+	}
+
+	frames, pixels := 0, int64(0)
+	for pos < len(data) {
+		switch data[pos] {
+		case 0x3B: // Trailer
+			return nil
+		case 0x21: // Extension: label lalu sub-block
+			if pos+2 > len(data) {
+				return errors.New("File GIF terpotong")
+			}
+			next, err := skipGIFSubBlocks(data, pos+2)
+			if err != nil {
+				return err
+			}
+			pos = next
+		case 0x2C: // Image descriptor
+			if pos+10 > len(data) {
+				return errors.New("File GIF terpotong")
+			}
+			width := int64(binary.LittleEndian.Uint16(data[pos+5:]))
+			height := int64(binary.LittleEndian.Uint16(data[pos+7:]))
+			flags := data[pos+9]
+			pos += 10
+			if flags&0x80 != 0 {
+				pos += 3 << ((flags & 0x07) + 1)
+			}
+
+			frames++
+			pixels += width * height
+			if frames > MaxGIFFrames {
+				return fmt.Errorf("Animasi GIF terlalu panjang, maksimal %d frame", MaxGIFFrames)
+			}
+			if pixels > MaxImagePixels {
+				return fmt.Errorf("Animasi GIF terlalu besar, total pixel semua frame maksimal %d megapixel", MaxImagePixels/1_000_000)
+			}
+
+			// Byte LZW minimum code size lalu data gambar dalam sub-block
+			next, err := skipGIFSubBlocks(data, pos+1)
+			if err != nil {
+				return err
+			}
+			pos = next
+		default:
+			return errors.New("File GIF tidak valid")
+		}
+	}
+	return nil
+}
+
+// skipGIFSubBlocks melewati rangkaian sub-block (byte ukuran + data) sampai block terminator
+func skipGIFSubBlocks(data []byte, pos int) (int, error) {
+	for {
+		if pos >= len(data) {
+			return 0, errors.New("File GIF terpotong")
+		}
+		size := int(data[pos])
+		pos++
+		if size == 0 {
+			return pos, nil
+		}
+		pos += size
+	}
+}
@@ -0,0 +1,201 @@
+// Package imaging memproses foto upload: koreksi orientasi EXIF, membuang metadata
+// (EXIF/GPS, XMP, IPTC), resize ke beberapa ukuran standar (rendition), memberi watermark
+// dan encode ke JPEG atau WebP.
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"strings"
+
+	_ "image/gif"
+	_ "image/png"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Format output rendition
+const (
+	FormatJPEG = "jpeg"
+	FormatWebP = "webp"
+)
+
+// JPEGQuality kualitas encode JPEG untuk semua rendition
+const JPEGQuality = 82
+
+// Batas dimensi gambar yang boleh di-decode, mencegah decompression bomb
+const (
+	MaxImageDimension = 12000      // Sisi terpanjang maksimal (pixel)
+	MaxImagePixels    = 60_000_000 // Total pixel maksimal (60MP)
+
+	// GIF animasi di-decode semua frame-nya: jumlah frame dan total pixel semua frame ikut dibatasi
+	MaxGIFFrames = 300
+)
+
+// Spec ukuran satu rendition, MaxSize adalah sisi terpanjang dalam pixel
+type Spec struct {
+	Name    string
+	MaxSize int
+}
+
+// DefaultSpecs rendition standar: thumb untuk galeri kecil, card untuk list, full untuk detail
+var DefaultSpecs = []Spec{
+	{Name: "thumb", MaxSize: 320},
+	{Name: "card", MaxSize: 800},
+	{Name: "full", MaxSize: 1920},
+}
+
+// Output hasil encode satu rendition
+type Output struct {
+	Name        string
+	Width       int
+	Height      int
+	Format      string
+	ContentType string
+	Data        []byte
+}
+
+// Ext ekstensi file sesuai format, contoh ".webp"
+func (o Output) Ext() string {
+	switch o.Format {
+	case FormatWebP:
+		return ".webp"
+	case "png":
+		return ".png"
+	case "gif":
+		return ".gif"
+	}
+	return ".jpg"
+}
+
+// Result hasil pemrosesan satu foto
+type Result struct {
+	Width      int    // Lebar foto asli setelah koreksi orientasi
+	Height     int    // Tinggi foto asli setelah koreksi orientasi
+	Format     string // Format foto asli (jpeg, png, gif, webp)
+	Hash       uint64 // Perceptual hash (dHash) untuk deteksi foto duplikat
+	Original   Output // Foto asli yang sudah di-encode ulang tanpa metadata
+	Metadata   MetadataReport
+	Renditions []Output
+
+	// Public salinan foto asli ber-watermark untuk URL publik, nil kalau tanpa watermark.
+	// Kalau ada, Original hanya boleh disimpan di storage private.
+	Public *Output
+}
+
+// Processor membuat rendition dari foto upload
+type Processor struct {
+	Specs  []Spec
+	Format string
+}
+
+// NewProcessorFromEnv membuat Processor dengan format dari IMAGE_RENDITION_FORMAT (jpeg atau webp).
+// Default jpeg karena encoder WebP yang tersedia (pure Go) hanya lossless, ukurannya lebih besar untuk foto.
+func NewProcessorFromEnv() *Processor {
+	format := FormatJPEG
+	if strings.ToLower(os.Getenv("IMAGE_RENDITION_FORMAT")) == FormatWebP {
+		format = FormatWebP
+	}
+	return &Processor{Specs: DefaultSpecs, Format: format}
+}
+
+// Process decode foto, memutar sesuai orientasi EXIF, membuang metadata dari foto asli
+// (kecuali tag di keepTags) lalu membuat semua rendition. Kalau wm diisi, rendition dan
+// salinan publik foto asli diberi watermark, sedangkan Original tetap bersih.
+func (p *Processor) Process(data []byte, keepTags []string, wm *Watermark) (*Result, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("Gagal decode gambar: %v", err)
+	}
+	if format == "jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	bounds := img.Bounds()
+	result := &Result{Width: bounds.Dx(), Height: bounds.Dy(), Format: format, Hash: DifferenceHash(img)}
+
+	result.Original, result.Metadata, err = encodeOriginal(img, data, format, keepTags)
+	if err != nil {
+		return nil, fmt.Errorf("Gagal encode ulang foto asli: %v", err)
+	}
+	if wm != nil {
+		public, err := encode(wm.Apply(img), p.Format)
+		if err != nil {
+			return nil, fmt.Errorf("Gagal encode foto ber-watermark: %v", err)
+		}
+		public.Name = "public"
+		result.Public = &public
+	}
+	for _, spec := range p.Specs {
+		resized := Resize(img, spec.MaxSize)
+		if wm != nil {
+			resized = wm.Apply(resized)
+		}
+		out, err := encode(resized, p.Format)
+		if err != nil {
+			return nil, fmt.Errorf("Gagal encode rendition %s: %v", spec.Name, err)
+		}
+		out.Name = spec.Name
+		result.Renditions = append(result.Renditions, out)
+	}
+	return result, nil
+}
+
+// Resize memperkecil gambar supaya sisi terpanjang maksimal maxSize, tidak pernah memperbesar
+func Resize(img image.Image, maxSize int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= maxSize && h <= maxSize {
+		return img
+	}
+
+	if w >= h {
+		h = max(1, h*maxSize/w)
+		w = maxSize
+	} else {
+		w = max(1, w*maxSize/h)
+		h = maxSize
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+func encode(img image.Image, format string) (Output, error) {
+	var buf bytes.Buffer
+	bounds := img.Bounds()
+	out := Output{Width: bounds.Dx(), Height: bounds.Dy(), Format: format}
+
+	if format == FormatWebP {
+		if err := nativewebp.Encode(&buf, img, nil); err != nil {
+			return out, err
+		}
+		out.ContentType = "image/webp"
+	} else {
+		if err := jpeg.Encode(&buf, flatten(img), &jpeg.Options{Quality: JPEGQuality}); err != nil {
+			return out, err
+		}
+		out.ContentType = "image/jpeg"
+	}
+	out.Data = buf.Bytes()
+	return out, nil
+}
+
+// flatten memberi latar putih untuk gambar transparan sebelum di-encode ke JPEG
+func flatten(img image.Image) image.Image {
+	if o, ok := img.(interface{ Opaque() bool }); ok && o.Opaque() {
+		return img
+	}
+	bounds := img.Bounds()
+	canvas := image.NewRGBA(bounds)
+	draw.Draw(canvas, bounds, &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(canvas, bounds, img, bounds.Min, draw.Over)
+	return canvas
+}
@@ -0,0 +1,197 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"sort"
+
+	"github.com/HugoSmits86/nativewebp"
+)
+
+// OriginalJPEGQuality kualitas encode ulang foto asli JPEG (lebih tinggi dari rendition)
+const OriginalJPEGQuality = 92
+
+// MetadataReport catatan metadata yang dibuang dari foto asli
+type MetadataReport struct {
+	Stripped bool     // Foto asli sudah di-encode ulang tanpa metadata
+	Removed  []string // Jenis metadata yang ditemukan lalu dibuang: exif, gps, xmp, iptc, comment, text
+	Kept     []string // Tag EXIF yang dipertahankan sesuai allowlist
+}
+
+// detectMetadata mencari jenis metadata yang ada di file asli
+func detectMetadata(data []byte, format string) []string {
+	found := map[string]bool{}
+	switch format {
+	case "jpeg":
+		for _, seg := range jpegSegments(data) {
+			switch {
+			case isExifSegment(seg):
+				found["exif"] = true
+				if parseExif(seg.payload[6:]).hasGPS {
+					found["gps"] = true
+				}
+			case seg.marker == 0xE1 && bytes.HasPrefix(seg.payload, []byte("http://ns.adobe.com/xap/1.0/")):
+				found["xmp"] = true
+			case seg.marker == 0xED:
+				found["iptc"] = true
+			case seg.marker == 0xFE:
+				found["comment"] = true
+			}
+		}
+	case "png":
+		for _, chunk := range pngChunks(data) {
+			switch chunk.kind {
+			case "eXIf":
+				found["exif"] = true
+			case "iTXt":
+				if bytes.HasPrefix(chunk.data, []byte("XML:com.adobe.xmp\x00")) {
+					found["xmp"] = true
+				} else {
+					found["text"] = true
+				}
+			case "tEXt", "zTXt":
+				found["text"] = true
+			}
+		}
+	case "webp":
+		if len(data) > 12 {
+			body := data[12:]
+			for len(body) >= 8 {
+				kind := string(body[:4])
+				size := int(binary.LittleEndian.Uint32(body[4:8]))
+				switch kind {
+				case "EXIF":
+					found["exif"] = true
+				case "XMP ":
+					found["xmp"] = true
+				}
+				next := 8 + size + size%2
+				if size < 0 || next > len(body) {
+					break
+				}
+				body = body[next:]
+			}
+		}
+	case "gif":
+		if bytes.Contains(data, []byte("XMP DataXMP")) {
+			found["xmp"] = true
+		}
+		if bytes.Contains(data, []byte{0x21, 0xFE}) {
+			found["comment"] = true
+		}
+	}
+
+	removed := make([]string, 0, len(found))
+	for kind := range found {
+		removed = append(removed, kind)
+	}
+	sort.Strings(removed)
+	return removed
+}
+
+type pngChunk struct {
+	kind string
+	data []byte
+}
+
+func pngChunks(data []byte) []pngChunk {
+	if len(data) < 8 || string(data[1:4]) != "PNG" {
+		return nil
+	}
+	var chunks []pngChunk
+	pos := 8
+	for pos+12 <= len(data) {
+		size := int(binary.BigEndian.Uint32(data[pos : pos+4]))
+		if size < 0 || pos+12+size > len(data) {
+			break
+		}
+		chunks = append(chunks, pngChunk{kind: string(data[pos+4 : pos+8]), data: data[pos+8 : pos+8+size]})
+		pos += 12 + size
+	}
+	return chunks
+}
+
+// encodeOriginal meng-encode ulang foto asli tanpa metadata. Orientasi EXIF sudah
+// diterapkan ke pixel sehingga tag Orientation tidak perlu disimpan lagi.
+// Untuk JPEG, tag teks yang ada di allowlist ditulis ulang ke segment EXIF baru.
+func encodeOriginal(img image.Image, data []byte, format string, keep []string) (Output, MetadataReport, error) {
+	report := MetadataReport{Stripped: true, Removed: detectMetadata(data, format)}
+	bounds := img.Bounds()
+	out := Output{Name: "original", Width: bounds.Dx(), Height: bounds.Dy(), Format: format}
+
+	var buf bytes.Buffer
+	switch format {
+	case "png":
+		if err := png.Encode(&buf, img); err != nil {
+			return out, report, err
+		}
+		out.ContentType = "image/png"
+	case "gif":
+		// DecodeAll/EncodeAll supaya animasi tetap ada; extension komentar dan XMP tidak ikut ditulis.
+		// Jumlah frame dicek dulu karena DecodeAll mengalokasikan semua frame sekaligus.
+		if err := CheckGIFFrames(data); err != nil {
+			return out, report, err
+		}
+		anim, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return out, report, err
+		}
+		if err := gif.EncodeAll(&buf, anim); err != nil {
+			return out, report, err
+		}
+		out.ContentType = "image/gif"
+	case "webp":
+		if err := nativewebp.Encode(&buf, img, nil); err != nil {
+			return out, report, err
+		}
+		out.ContentType = "image/webp"
+	default:
+		out.Format = FormatJPEG
+		var encoded bytes.Buffer
+		if err := jpeg.Encode(&encoded, flatten(img), &jpeg.Options{Quality: OriginalJPEGQuality}); err != nil {
+			return out, report, err
+		}
+
+		kept := keptExifTags(data, keep)
+		segment := buildExifSegment(kept)
+		raw := encoded.Bytes()
+		buf.Write(raw[:2]) // SOI
+		buf.Write(segment)
+		buf.Write(raw[2:])
+		if segment != nil {
+			for name := range kept {
+				report.Kept = append(report.Kept, name)
+			}
+			sort.Strings(report.Kept)
+		}
+		out.ContentType = "image/jpeg"
+	}
+
+	out.Data = buf.Bytes()
+	return out, report, nil
+}
+
+// keptExifTags mengambil tag teks dari EXIF asli yang namanya ada di allowlist
+func keptExifTags(data []byte, keep []string) map[string]string {
+	kept := map[string]string{}
+	if len(keep) == 0 {
+		return kept
+	}
+	for _, seg := range jpegSegments(data) {
+		if !isExifSegment(seg) {
+			continue
+		}
+		info := parseExif(seg.payload[6:])
+		for _, name := range keep {
+			if value, ok := info.text[name]; ok && IsAllowedMetadataTag(name) {
+				kept[name] = value
+			}
+		}
+		break
+	}
+	return kept
+}
@@ -0,0 +1,56 @@
+package imaging
+
+import (
+	"image"
+)
+
+// jpegOrientation membaca tag Orientation (0x0112) dari segment EXIF APP1.
+// Mengembalikan 1 (normal) kalau tidak ada EXIF atau datanya rusak.
+func jpegOrientation(data []byte) int {
+	for _, seg := range jpegSegments(data) {
+		if isExifSegment(seg) {
+			return parseExif(seg.payload[6:]).orientation
+		}
+	}
+	return 1
+}
+
+// applyOrientation memutar/membalik gambar sesuai nilai orientasi EXIF (1-8)
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	// Orientasi 5-8 menukar lebar dan tinggi
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // flip horizontal
+				dx, dy = w-1-x, y
+			case 3: // rotate 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // flip vertical
+				dx, dy = x, h-1-y
+			case 5: // transpose
+				dx, dy = y, x
+			case 6: // rotate 90 searah jarum jam
+				dx, dy = h-1-y, x
+			case 7: // transverse
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotate 90 berlawanan jarum jam
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,35 @@
+package imaging
+
+import (
+	"image"
+	"math/bits"
+
+	"golang.org/x/image/draw"
+)
+
+// NearDuplicateDistance batas jarak Hamming (dari 64 bit) untuk dianggap foto yang sama/mirip
+const NearDuplicateDistance = 6
+
+// DifferenceHash menghitung perceptual hash (dHash) 64 bit: gambar diperkecil ke 9x8 grayscale
+// lalu tiap bit menandakan apakah pixel lebih terang dari pixel di kanannya. Tahan terhadap
+// resize, kompresi ulang dan perubahan warna kecil, tapi tidak terhadap crop atau rotasi.
+func DifferenceHash(img image.Image) uint64 {
+	small := image.NewGray(image.Rect(0, 0, 9, 8))
+	draw.BiLinear.Scale(small, small.Bounds(), img, img.Bounds(), draw.Src, nil)
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if small.GrayAt(x, y).Y < small.GrayAt(x+1, y).Y {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// HashDistance jarak Hamming antara dua hash, 0 berarti identik
+func HashDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
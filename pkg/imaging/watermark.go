@@ -0,0 +1,152 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"sync"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Posisi watermark di foto
+const (
+	PositionTopLeft     = "top-left"
+	PositionTopRight    = "top-right"
+	PositionBottomLeft  = "bottom-left"
+	PositionBottomRight = "bottom-right"
+	PositionCenter      = "center"
+)
+
+// WatermarkPositions semua posisi yang valid
+var WatermarkPositions = []string{PositionTopLeft, PositionTopRight, PositionBottomLeft, PositionBottomRight, PositionCenter}
+
+// Batas watermark supaya tetap terbaca tapi tidak menutupi foto
+const (
+	DefaultWatermarkOpacity = 0.5
+	DefaultWatermarkScale   = 0.25
+	minWatermarkTextSize    = 10 // Ukuran font minimal (pixel) untuk rendition kecil
+)
+
+// Watermark logo atau teks yang ditempel ke rendition publik. Kalau Logo diisi, Text diabaikan.
+type Watermark struct {
+	Text     string
+	Logo     image.Image
+	Position string
+	Opacity  float64 // 0-1
+	Scale    float64 // Lebar watermark relatif terhadap lebar foto, contoh 0.25
+}
+
+// Apply menempel watermark ke salinan gambar, gambar asli tidak diubah
+func (w *Watermark) Apply(img image.Image) image.Image {
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+
+	scale := w.Scale
+	if scale <= 0 || scale > 1 {
+		scale = DefaultWatermarkScale
+	}
+	opacity := w.Opacity
+	if opacity <= 0 || opacity > 1 {
+		opacity = DefaultWatermarkOpacity
+	}
+
+	targetWidth := max(1, int(float64(dst.Bounds().Dx())*scale))
+	var mark image.Image
+	if w.Logo != nil {
+		mark = scaleLogo(w.Logo, targetWidth)
+	} else if w.Text != "" {
+		mark = renderText(w.Text, targetWidth)
+	}
+	if mark == nil {
+		return dst
+	}
+
+	markBounds := mark.Bounds()
+	margin := min(dst.Bounds().Dx(), dst.Bounds().Dy()) * 3 / 100
+	at := watermarkOrigin(w.Position, dst.Bounds(), markBounds.Dx(), markBounds.Dy(), margin)
+	mask := image.NewUniform(color.Alpha{A: uint8(opacity * 255)})
+	draw.DrawMask(dst, image.Rectangle{Min: at, Max: at.Add(markBounds.Size())}, mark, markBounds.Min, mask, image.Point{}, draw.Over)
+	return dst
+}
+
+// watermarkOrigin titik kiri atas watermark sesuai posisi
+func watermarkOrigin(position string, canvas image.Rectangle, w, h, margin int) image.Point {
+	switch position {
+	case PositionTopLeft:
+		return image.Pt(margin, margin)
+	case PositionTopRight:
+		return image.Pt(canvas.Dx()-w-margin, margin)
+	case PositionBottomLeft:
+		return image.Pt(margin, canvas.Dy()-h-margin)
+	case PositionCenter:
+		return image.Pt((canvas.Dx()-w)/2, (canvas.Dy()-h)/2)
+	}
+	return image.Pt(canvas.Dx()-w-margin, canvas.Dy()-h-margin)
+}
+
+// scaleLogo mengubah lebar logo ke targetWidth dengan rasio tetap
+func scaleLogo(logo image.Image, targetWidth int) image.Image {
+	bounds := logo.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		return nil
+	}
+	height := max(1, bounds.Dy()*targetWidth/bounds.Dx())
+	dst := image.NewRGBA(image.Rect(0, 0, targetWidth, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), logo, bounds, draw.Over, nil)
+	return dst
+}
+
+var (
+	watermarkFont     *opentype.Font
+	watermarkFontErr  error
+	watermarkFontOnce sync.Once
+)
+
+// renderText menggambar teks putih dengan bayangan gelap di latar transparan,
+// ukuran font disesuaikan supaya lebar teks sekitar targetWidth
+func renderText(text string, targetWidth int) image.Image {
+	watermarkFontOnce.Do(func() {
+		watermarkFont, watermarkFontErr = opentype.Parse(gobold.TTF)
+	})
+	if watermarkFontErr != nil {
+		return nil
+	}
+
+	const probeSize = 100
+	probe, err := opentype.NewFace(watermarkFont, &opentype.FaceOptions{Size: probeSize, DPI: 72})
+	if err != nil {
+		return nil
+	}
+	probeWidth := font.MeasureString(probe, text).Ceil()
+	probe.Close()
+	if probeWidth == 0 {
+		return nil
+	}
+
+	size := max(float64(minWatermarkTextSize), probeSize*float64(targetWidth)/float64(probeWidth))
+	face, err := opentype.NewFace(watermarkFont, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil
+	}
+	defer face.Close()
+
+	metrics := face.Metrics()
+	shadow := max(1, int(size/24))
+	width := font.MeasureString(face, text).Ceil() + shadow
+	height := (metrics.Ascent + metrics.Descent).Ceil() + shadow
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	drawer := &font.Drawer{Dst: dst, Face: face}
+	drawer.Src = image.NewUniform(color.RGBA{A: 160})
+	drawer.Dot = fixed.Point26_6{X: fixed.I(shadow), Y: metrics.Ascent + fixed.I(shadow)}
+	drawer.DrawString(text)
+	drawer.Src = image.White
+	drawer.Dot = fixed.Point26_6{X: 0, Y: metrics.Ascent}
+	drawer.DrawString(text)
+	return dst
+}
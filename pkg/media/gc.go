@@ -0,0 +1,184 @@
+// Package media berisi job pemeliharaan file upload, seperti pembersihan file yatim (orphan).
+package media
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"project-zero/internal/models"
+	"project-zero/pkg/database"
+	"project-zero/pkg/storage"
+	"time"
+)
+
+// Default konfigurasi GC, bisa diubah lewat MEDIA_GC_INTERVAL dan MEDIA_GC_GRACE
+const (
+	DefaultGCInterval = 6 * time.Hour
+	DefaultGCGrace    = 24 * time.Hour
+	GCBatchSize       = 500
+)
+
+// OrphanItem satu asset yang (akan) dihapus GC
+type OrphanItem struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"user_id"`
+	URL       string    `json:"url"`
+	Status    string    `json:"status"`
+	Size      int64     `json:"size"`
+	Files     int       `json:"files"` // Jumlah file di storage: asli + rendition (+ asli tanpa watermark)
+	IdleSince time.Time `json:"idle_since"`
+	Reason    string    `json:"reason"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// GCReport hasil satu kali GC (atau dry-run)
+type GCReport struct {
+	DryRun     bool         `json:"dry_run"`
+	Grace      string       `json:"grace_period"`
+	Cutoff     time.Time    `json:"cutoff"`
+	Items      []OrphanItem `json:"items"`
+	Deleted    int          `json:"deleted"`
+	FreedBytes int64        `json:"freed_bytes"`
+	Failed     int          `json:"failed"`
+}
+
+// Collector menghapus asset yang tidak dipakai listing/foto mana pun setelah grace period
+type Collector struct {
+	assets  *database.MediaAssetRepository
+	store   storage.Storage
+	private storage.Storage // Foto asli tanpa watermark
+	Grace   time.Duration
+}
+
+func NewCollector(assets *database.MediaAssetRepository, store, private storage.Storage, grace time.Duration) *Collector {
+	return &Collector{assets: assets, store: store, private: private, Grace: grace}
+}
+
+// Run mencari asset orphan dan menghapus file serta catatannya. Kalau dryRun, hanya membuat laporan.
+func (gc *Collector) Run(ctx context.Context, dryRun bool) (*GCReport, error) {
+	cutoff := time.Now().Add(-gc.Grace)
+	report := &GCReport{DryRun: dryRun, Grace: gc.Grace.String(), Cutoff: cutoff, Items: []OrphanItem{}}
+
+	orphans, err := gc.assets.FindOrphans(cutoff, GCBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range orphans {
+		asset := &orphans[i]
+		keys := gc.assetKeys(asset)
+		item := OrphanItem{
+			ID:        asset.ID,
+			UserID:    asset.UserID,
+			URL:       asset.URL,
+			Status:    asset.Status,
+			Size:      asset.Size,
+			Files:     len(keys) + boolToInt(asset.OriginalKey != ""),
+			IdleSince: asset.CreatedAt,
+			Reason:    "tidak pernah dipakai listing",
+		}
+		if asset.DetachedAt != nil {
+			item.IdleSince = *asset.DetachedAt
+			item.Reason = "foto/listing sudah dihapus"
+		}
+		if asset.Status == models.MediaStatusPending {
+			item.Reason = "upload langsung tidak dikonfirmasi"
+		}
+
+		if !dryRun {
+			if err := gc.deleteAsset(ctx, asset, keys); err != nil {
+				item.Error = err.Error()
+				report.Failed++
+			} else {
+				report.Deleted++
+				report.FreedBytes += asset.Size
+			}
+		}
+		report.Items = append(report.Items, item)
+	}
+	return report, nil
+}
+
+// Start menjalankan GC berkala sampai ctx selesai. interval <= 0 berarti GC otomatis dimatikan.
+func (gc *Collector) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				report, err := gc.Run(ctx, false)
+				if err != nil {
+					fmt.Printf("⚠️  Media GC gagal: %v\n", err)
+					continue
+				}
+				if report.Deleted > 0 || report.Failed > 0 {
+					fmt.Printf("🧹 Media GC: %d asset dihapus (%d bytes), %d gagal\n", report.Deleted, report.FreedBytes, report.Failed)
+				}
+			}
+		}
+	}()
+}
+
+// assetKeys key storage file asli dan semua rendition asset
+func (gc *Collector) assetKeys(asset *models.MediaAsset) []string {
+	keys := []string{}
+	if asset.StorageKey != "" {
+		keys = append(keys, asset.StorageKey)
+	} else if key, ok := gc.store.KeyFromURL(asset.URL); ok {
+		keys = append(keys, key)
+	}
+	for _, rendition := range asset.Renditions {
+		if key, ok := gc.store.KeyFromURL(rendition.URL); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// deleteAsset menghapus catatan asset (hanya kalau masih orphan saat ini) lalu file-nya di storage.
+// Catatan dihapus duluan supaya asset yang baru saja dipasang ke listing tidak ikut terhapus.
+func (gc *Collector) deleteAsset(ctx context.Context, asset *models.MediaAsset, keys []string) error {
+	deleted, err := gc.assets.DeleteIfOrphan(asset)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("asset sudah dipakai lagi, dilewati")
+	}
+	for _, key := range keys {
+		if err := gc.store.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	if asset.OriginalKey != "" {
+		return gc.private.Delete(ctx, asset.OriginalKey)
+	}
+	return nil
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// DurationFromEnv membaca durasi dari env (format Go, contoh "6h", "30m"), fallback kalau kosong/tidak valid
+func DurationFromEnv(key string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		fmt.Printf("⚠️  %s tidak valid (%q), pakai default %s\n", key, raw, fallback)
+		return fallback
+	}
+	return d
+}
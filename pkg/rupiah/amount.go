@@ -0,0 +1,47 @@
+package rupiah
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+)
+
+// Amount adalah nominal rupiah yang bisa di-unmarshal dari angka JSON
+// maupun string yang diketik user ("1,5 M", "750jt", "Rp 1.500.000")
+type Amount int64
+
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		if text == "" {
+			*a = 0
+			return nil
+		}
+		amount, err := Parse(text)
+		if err != nil {
+			return err
+		}
+		*a = Amount(amount)
+		return nil
+	}
+
+	if n, err := strconv.ParseInt(string(data), 10, 64); err == nil {
+		*a = Amount(n)
+		return nil
+	}
+	f, err := strconv.ParseFloat(string(data), 64)
+	if err != nil || f > math.MaxInt64 || f < math.MinInt64 {
+		return ErrInvalidAmount
+	}
+	*a = Amount(math.Round(f))
+	return nil
+}
@@ -0,0 +1,80 @@
+package rupiah
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FormatNumber memberi pemisah ribuan titik, contoh: 1500000 -> "1.500.000"
+func FormatNumber(amount int64) string {
+	sign := ""
+	digits := strconv.FormatInt(amount, 10)
+	if amount < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(d)
+	}
+	return sign + b.String()
+}
+
+// Format memformat nominal lengkap, contoh: 1500000 -> "Rp 1.500.000"
+func Format(amount int64) string {
+	return "Rp " + FormatNumber(amount)
+}
+
+var satuan = []string{"", "satu", "dua", "tiga", "empat", "lima", "enam", "tujuh", "delapan", "sembilan", "sepuluh", "sebelas"}
+
+// Terbilang mengubah nominal menjadi kata-kata, contoh: 1500000 -> "satu juta lima ratus ribu rupiah"
+func Terbilang(amount int64) string {
+	if amount == 0 {
+		return "nol rupiah"
+	}
+	words := terbilang(amount)
+	if amount < 0 {
+		words = "minus " + terbilang(-amount)
+	}
+	return words + " rupiah"
+}
+
+func terbilang(n int64) string {
+	switch {
+	case n < 12:
+		return satuan[n]
+	case n < 20:
+		return join(terbilang(n-10), "belas")
+	case n < 100:
+		return join(terbilang(n/10), "puluh", terbilang(n%10))
+	case n < 200:
+		return join("seratus", terbilang(n-100))
+	case n < 1_000:
+		return join(terbilang(n/100), "ratus", terbilang(n%100))
+	case n < 2_000:
+		return join("seribu", terbilang(n-1_000))
+	case n < 1_000_000:
+		return join(terbilang(n/1_000), "ribu", terbilang(n%1_000))
+	case n < 1_000_000_000:
+		return join(terbilang(n/1_000_000), "juta", terbilang(n%1_000_000))
+	case n < 1_000_000_000_000:
+		return join(terbilang(n/1_000_000_000), "miliar", terbilang(n%1_000_000_000))
+	case n < 1_000_000_000_000_000:
+		return join(terbilang(n/1_000_000_000_000), "triliun", terbilang(n%1_000_000_000_000))
+	default:
+		return join(terbilang(n/1_000_000_000_000_000), "kuadriliun", terbilang(n%1_000_000_000_000_000))
+	}
+}
+
+func join(parts ...string) string {
+	var words []string
+	for _, p := range parts {
+		if p != "" {
+			words = append(words, p)
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,151 @@
+package rupiah
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidAmount dikembalikan kalau teks harga tidak bisa dibaca
+var ErrInvalidAmount = errors.New("format harga tidak valid")
+
+// multipliers singkatan nominal yang umum dipakai di Indonesia.
+// "M" berarti miliar (bukan million), sesuai kebiasaan iklan properti.
+var multipliers = map[string]int64{
+	"":        1,
+	"rb":      1_000,
+	"ribu":    1_000,
+	"k":       1_000,
+	"jt":      1_000_000,
+	"juta":    1_000_000,
+	"m":       1_000_000_000,
+	"miliar":  1_000_000_000,
+	"milyar":  1_000_000_000,
+	"b":       1_000_000_000,
+	"t":       1_000_000_000_000,
+	"triliun": 1_000_000_000_000,
+	"trilyun": 1_000_000_000_000,
+}
+
+// Parse membaca harga yang diketik user, contoh:
+// "1500000", "Rp 1.500.000", "1,5 M", "750jt", "1.2 miliar", "500 rb", "2T".
+//
+// Titik dan koma dibaca sebagai pemisah ribuan kalau diikuti tepat 3 digit
+// ("1.500.000", "1,500 jt"), selain itu sebagai desimal ("1,5 M", "1.25 jt").
+// Kalau keduanya muncul, yang terakhir adalah desimal ("1.250.000,50").
+func Parse(input string) (int64, error) {
+	s := strings.ToLower(strings.TrimSpace(input))
+	s = strings.TrimPrefix(s, "rp")
+	s = strings.TrimPrefix(s, "idr")
+	s = strings.ReplaceAll(s, " ", "")
+	s = strings.TrimPrefix(s, ".")
+
+	// Pisahkan angka dan satuan di belakangnya
+	split := strings.IndexFunc(s, unicode.IsLetter)
+	number, unit := s, ""
+	if split >= 0 {
+		number, unit = s[:split], s[split:]
+	}
+
+	multiplier, ok := multipliers[unit]
+	if !ok || number == "" {
+		return 0, ErrInvalidAmount
+	}
+
+	intPart, fracPart, err := splitDecimal(number)
+	if err != nil {
+		return 0, err
+	}
+
+	whole, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+	if whole > math.MaxInt64/multiplier {
+		return 0, ErrInvalidAmount
+	}
+	amount := whole * multiplier
+
+	// Bagian desimal dihitung dengan integer supaya tidak ada error floating point
+	if fracPart != "" {
+		scale := int64(1)
+		for range fracPart {
+			if scale > math.MaxInt64/10 {
+				return 0, ErrInvalidAmount
+			}
+			scale *= 10
+		}
+		frac, err := strconv.ParseInt(fracPart, 10, 64)
+		if err != nil {
+			return 0, ErrInvalidAmount
+		}
+		if frac > 0 && multiplier > math.MaxInt64/frac {
+			return 0, ErrInvalidAmount
+		}
+		extra := (frac*multiplier + scale/2) / scale // dibulatkan ke rupiah terdekat
+		if amount > math.MaxInt64-extra {
+			return 0, ErrInvalidAmount
+		}
+		amount += extra
+	}
+
+	return amount, nil
+}
+
+// splitDecimal memisahkan bagian bulat dan desimal, sekaligus membuang pemisah ribuan
+func splitDecimal(number string) (string, string, error) {
+	hasDigit := false
+	for _, r := range number {
+		if unicode.IsDigit(r) {
+			hasDigit = true
+		} else if r != '.' && r != ',' {
+			return "", "", ErrInvalidAmount
+		}
+	}
+	if !hasDigit {
+		return "", "", ErrInvalidAmount
+	}
+
+	lastDot := strings.LastIndex(number, ".")
+	lastComma := strings.LastIndex(number, ",")
+
+	decimalAt := -1
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		decimalAt = max(lastDot, lastComma)
+	case lastDot >= 0 || lastComma >= 0:
+		sep := byte('.')
+		if lastComma >= 0 {
+			sep = ','
+		}
+		at := max(lastDot, lastComma)
+		// Satu pemisah yang diikuti selain 3 digit berarti desimal
+		if strings.Count(number, string(sep)) == 1 && len(number)-at-1 != 3 {
+			decimalAt = at
+		}
+	}
+
+	intPart, fracPart := number, ""
+	if decimalAt >= 0 {
+		intPart, fracPart = number[:decimalAt], number[decimalAt+1:]
+		if strings.ContainsAny(fracPart, ".,") {
+			return "", "", ErrInvalidAmount
+		}
+	}
+
+	// Pemisah ribuan harus memisahkan kelompok 3 digit
+	groups := strings.FieldsFunc(intPart, func(r rune) bool { return r == '.' || r == ',' })
+	for i, g := range groups {
+		if i > 0 && len(g) != 3 {
+			return "", "", ErrInvalidAmount
+		}
+	}
+
+	intPart = strings.Join(groups, "")
+	if intPart == "" {
+		intPart = "0"
+	}
+	return intPart, fracPart, nil
+}
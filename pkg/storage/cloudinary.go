@@ -0,0 +1,245 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/admin"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+)
+
+var videoExtensions = map[string]bool{".mp4": true, ".webm": true, ".mov": true}
+
+// cloudinaryPathPattern menangkap cloud name, resource type dan public ID dari URL delivery Cloudinary,
+// contoh: https://res.cloudinary.com/demo/image/upload/v1700000000/property-photos/x.jpg
+var cloudinaryPathPattern = regexp.MustCompile(`^/([^/]+)/(image|video|raw)/upload/(?:s--[^/]+--/)?(?:v\d+/)?(.+)$`)
+
+// CloudinaryStorage menyimpan file di Cloudinary. Key dipakai sebagai public ID
+// (tanpa ekstensi), ekstensi key menentukan resource type (image/video/raw).
+// File diupload dengan delivery type "upload" yang selalu publik, jadi driver ini hanya
+// untuk storage publik dan tidak bisa membuat link yang kadaluarsa (lihat SignedURL).
+type CloudinaryStorage struct {
+	cld *cloudinary.Cloudinary
+}
+
+func NewCloudinaryStorage(cloudName, apiKey, apiSecret string) (*CloudinaryStorage, error) {
+	if cloudName == "" || apiKey == "" || apiSecret == "" {
+		return nil, fmt.Errorf("Cloudinary credentials belum diatur di .env")
+	}
+
+	cld, err := cloudinary.NewFromParams(cloudName, apiKey, apiSecret)
+	if err != nil {
+		return nil, fmt.Errorf("Gagal inisialisasi Cloudinary: %v", err)
+	}
+	return &CloudinaryStorage{cld: cld}, nil
+}
+
+func (s *CloudinaryStorage) Name() string { return DriverCloudinary }
+
+func (s *CloudinaryStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (string, error) {
+	publicID, resourceType := cloudinaryIdentity(key)
+	overwrite := false
+	result, err := s.cld.Upload.Upload(ctx, r, uploader.UploadParams{
+		PublicID:     publicID,
+		ResourceType: resourceType,
+		Overwrite:    &overwrite,
+	})
+	if err != nil {
+		return "", fmt.Errorf("Gagal upload ke Cloudinary: %v", err)
+	}
+	if result.Error.Message != "" {
+		return "", fmt.Errorf("Gagal upload ke Cloudinary: %s", result.Error.Message)
+	}
+
+	// Return secure URL (HTTPS)
+	return result.SecureURL, nil
+}
+
+func (s *CloudinaryStorage) Delete(ctx context.Context, key string) error {
+	publicID, resourceType := cloudinaryIdentity(key)
+	result, err := s.cld.Upload.Destroy(ctx, uploader.DestroyParams{
+		PublicID:     publicID,
+		ResourceType: resourceType,
+	})
+	if err != nil {
+		return fmt.Errorf("Gagal hapus dari Cloudinary: %v", err)
+	}
+	if result.Error.Message != "" {
+		return fmt.Errorf("Gagal hapus dari Cloudinary: %s", result.Error.Message)
+	}
+	return nil
+}
+
+func (s *CloudinaryStorage) Exists(ctx context.Context, key string) (bool, error) {
+	publicID, resourceType := cloudinaryIdentity(key)
+	result, err := s.cld.Admin.Asset(ctx, admin.AssetParams{
+		PublicID:     publicID,
+		AssetType:    api.AssetType(resourceType),
+		DeliveryType: api.Upload,
+	})
+	if err != nil {
+		return false, fmt.Errorf("Gagal cek file di Cloudinary: %v", err)
+	}
+	if result.Error.Message != "" {
+		if strings.Contains(strings.ToLower(result.Error.Message), "not found") {
+			return false, nil
+		}
+		return false, fmt.Errorf("Gagal cek file di Cloudinary: %s", result.Error.Message)
+	}
+	return result.PublicID != "", nil
+}
+
+// SignedURL selalu mengembalikan ErrExpiryUnsupported kalau expiry diisi: file Cloudinary
+// publik dan signed URL-nya tidak pernah kadaluarsa, jadi link sementara tidak bisa dijamin.
+// Fitur yang butuh link kadaluarsa memakai storage private (local/S3).
+func (s *CloudinaryStorage) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if expiry > 0 {
+		return "", ErrExpiryUnsupported
+	}
+	return s.deliveryURL(key)
+}
+
+// deliveryURL membuat delivery URL yang ditandatangani (tanpa batas waktu) untuk membaca file
+func (s *CloudinaryStorage) deliveryURL(key string) (string, error) {
+	publicID, resourceType := cloudinaryIdentity(key)
+
+	asset, err := s.cld.Image(publicID)
+	if resourceType == "video" {
+		asset, err = s.cld.Video(publicID)
+	} else if resourceType == "raw" {
+		asset, err = s.cld.File(publicID)
+	}
+	if err != nil {
+		return "", err
+	}
+	asset.Config.URL.Secure = true
+	asset.Config.URL.SignURL = true
+	if ext := path.Ext(key); ext != "" && resourceType != "raw" {
+		asset.PublicID = publicID + ext
+	}
+	return asset.String()
+}
+
+func (s *CloudinaryStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	signed, err := s.deliveryURL(key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, signed, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Gagal membaca file dari Cloudinary: %v", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("Gagal membaca file dari Cloudinary: status %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// URL membuat delivery URL (tanpa versi) dari key
+func (s *CloudinaryStorage) URL(key string) string {
+	publicID, resourceType := cloudinaryIdentity(key)
+	suffix := publicID
+	if ext := path.Ext(key); ext != "" && resourceType != "raw" {
+		suffix += ext
+	}
+	return fmt.Sprintf("https://res.cloudinary.com/%s/%s/upload/%s", s.cld.Config.Cloud.CloudName, resourceType, suffix)
+}
+
+// PresignUpload membuat parameter signed upload Cloudinary. Yang ikut ditandatangani hanya
+// public_id, timestamp dan allowed_formats (sesuai contentType), jadi:
+//   - ukuran file tidak bisa dibatasi lewat signature, dicek ulang saat konfirmasi
+//   - expiry tidak bisa dipilih, Cloudinary menerima signature sampai 1 jam setelah timestamp.
+//     ExpiresAt selalu 1 jam dan parameter expiry diabaikan.
+func (s *CloudinaryStorage) PresignUpload(ctx context.Context, key, contentType string, maxSize int64, expiry time.Duration) (*UploadTarget, error) {
+	format, ok := cloudinaryFormats[contentType]
+	if !ok {
+		return nil, fmt.Errorf("Tipe file %s tidak didukung untuk upload langsung", contentType)
+	}
+	publicID, resourceType := cloudinaryIdentity(key)
+	now := time.Now()
+
+	params := url.Values{}
+	params.Set("allowed_formats", format)
+	params.Set("public_id", publicID)
+	params.Set("timestamp", strconv.FormatInt(now.Unix(), 10))
+	signature, err := api.SignParameters(params, s.cld.Config.Cloud.APISecret)
+	if err != nil {
+		return nil, fmt.Errorf("Gagal membuat signed upload: %v", err)
+	}
+
+	return &UploadTarget{
+		Method: "POST",
+		URL:    fmt.Sprintf("https://api.cloudinary.com/v1_1/%s/%s/upload", s.cld.Config.Cloud.CloudName, resourceType),
+		Fields: map[string]string{
+			"api_key":         s.cld.Config.Cloud.APIKey,
+			"allowed_formats": format,
+			"public_id":       publicID,
+			"timestamp":       params.Get("timestamp"),
+			"signature":       signature,
+		},
+		FileField: "file",
+		ExpiresAt: now.Add(time.Hour),
+	}, nil
+}
+
+// cloudinaryFormats format Cloudinary (allowed_formats) untuk content type yang boleh diupload langsung
+var cloudinaryFormats = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+	"image/webp": "webp",
+}
+
+func (s *CloudinaryStorage) KeyFromURL(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host != "res.cloudinary.com" {
+		return "", false
+	}
+	// Hanya URL dari cloud sendiri, supaya URL cloud lain tidak diterjemahkan jadi key yang bisa dihapus
+	m := cloudinaryPathPattern.FindStringSubmatch(u.Path)
+	if m == nil || m[1] != s.cld.Config.Cloud.CloudName {
+		return "", false
+	}
+	return m[3], true
+}
+
+// cloudinaryIdentity mengubah key menjadi public ID dan resource type Cloudinary
+func cloudinaryIdentity(key string) (string, string) {
+	ext := strings.ToLower(path.Ext(key))
+	switch {
+	case videoExtensions[ext]:
+		return strings.TrimSuffix(key, path.Ext(key)), "video"
+	case ext == ".pdf" || ext == "" || isImageExt(ext):
+		return strings.TrimSuffix(key, path.Ext(key)), "image"
+	default:
+		// File raw (selain gambar/video) menyimpan ekstensi di public ID
+		return key, "raw"
+	}
+}
+
+func isImageExt(ext string) bool {
+	switch ext {
+	case ".jpg", ".jpeg", ".png", ".gif", ".webp", ".heic", ".avif":
+		return true
+	}
+	return false
+}
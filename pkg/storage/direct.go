@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// UploadTarget parameter upload langsung dari browser ke storage tanpa lewat server aplikasi
+type UploadTarget struct {
+	Method    string            `json:"method"`               // POST (multipart form) atau PUT (body = isi file)
+	URL       string            `json:"url"`                  // Tujuan upload
+	Fields    map[string]string `json:"fields,omitempty"`     // Field form yang wajib ikut dikirim (POST)
+	FileField string            `json:"file_field,omitempty"` // Nama field file di form (POST)
+	Headers   map[string]string `json:"headers,omitempty"`    // Header yang wajib ikut dikirim (PUT)
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// DirectUploader diimplementasikan driver yang mendukung upload langsung (signed upload)
+type DirectUploader interface {
+	// PresignUpload membuat parameter upload untuk satu key dengan batas ukuran dan waktu
+	PresignUpload(ctx context.Context, key, contentType string, maxSize int64, expiry time.Duration) (*UploadTarget, error)
+}
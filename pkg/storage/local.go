@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalConfig konfigurasi driver local disk
+type LocalConfig struct {
+	Dir           string // Folder penyimpanan, contoh: ./uploads
+	BaseURL       string // Prefix URL publik, contoh: /uploads atau https://cdn.domain.com/uploads. Kosong untuk storage private
+	SignedBaseURL string // Prefix URL untuk akses lewat signed URL, contoh: /storage
+	SigningSecret string
+}
+
+// LocalStorage menyimpan file di disk server. File publik di-serve dari BaseURL,
+// signed URL diverifikasi oleh handler yang dipasang di SignedBaseURL.
+type LocalStorage struct {
+	cfg LocalConfig
+}
+
+func NewLocalStorage(cfg LocalConfig) (*LocalStorage, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("Gagal membuat folder storage %s: %v", cfg.Dir, err)
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	cfg.SignedBaseURL = strings.TrimRight(cfg.SignedBaseURL, "/")
+	return &LocalStorage{cfg: cfg}, nil
+}
+
+func (s *LocalStorage) Name() string { return DriverLocal }
+
+// Dir folder root penyimpanan
+func (s *LocalStorage) Dir() string { return s.cfg.Dir }
+
+// BaseURL prefix URL publik
+func (s *LocalStorage) BaseURL() string { return s.cfg.BaseURL }
+
+// Path path file di disk untuk key tertentu
+func (s *LocalStorage) Path(key string) (string, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.cfg.Dir, filepath.FromSlash(cleaned)), nil
+}
+
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (string, error) {
+	dst, err := s.Path(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return "", fmt.Errorf("Gagal membuat folder: %v", err)
+	}
+
+	// Tulis ke file sementara lalu rename, supaya tidak ada file setengah jadi
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("Gagal membuat file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("Gagal menyimpan file: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("Gagal menyimpan file: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return "", fmt.Errorf("Gagal menyimpan file: %v", err)
+	}
+
+	return s.URL(key), nil
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	p, err := s.Path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("Gagal hapus file: %v", err)
+	}
+	return nil
+}
+
+func (s *LocalStorage) Exists(ctx context.Context, key string) (bool, error) {
+	p, err := s.Path(key)
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !info.IsDir(), nil
+}
+
+// SignedURL membuat URL dengan signature HMAC dan waktu kadaluarsa
+func (s *LocalStorage) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+
+	q := url.Values{}
+	q.Set("expires", expires)
+	q.Set("signature", s.sign(cleaned, expires))
+	return s.cfg.SignedBaseURL + "/" + cleaned + "?" + q.Encode(), nil
+}
+
+// VerifySignature memvalidasi signature dan expiry dari SignedURL
+func (s *LocalStorage) VerifySignature(key, expires, signature string) error {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return errors.New("link sudah kadaluarsa")
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.sign(cleaned, expires))) {
+		return errors.New("signature tidak valid")
+	}
+	return nil
+}
+
+func (s *LocalStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	p, err := s.Path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// URL publik file, string kosong untuk storage private (tanpa BaseURL)
+func (s *LocalStorage) URL(key string) string {
+	if s.cfg.BaseURL == "" {
+		return ""
+	}
+	cleaned, _ := cleanKey(key)
+	return s.cfg.BaseURL + "/" + cleaned
+}
+
+// PresignUpload membuat URL PUT ke SignedBaseURL dengan signature khusus upload.
+// Untuk local disk file tetap lewat server aplikasi, tapi alurnya sama dengan driver lain.
+func (s *LocalStorage) PresignUpload(ctx context.Context, key, contentType string, maxSize int64, expiry time.Duration) (*UploadTarget, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(expiry)
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+
+	q := url.Values{}
+	q.Set("expires", expires)
+	q.Set("signature", s.sign(uploadSigningKey(cleaned), expires))
+	return &UploadTarget{
+		Method:    "PUT",
+		URL:       s.cfg.SignedBaseURL + "/" + cleaned + "?" + q.Encode(),
+		Headers:   map[string]string{"Content-Type": contentType},
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// VerifyUploadSignature memvalidasi signature dari PresignUpload
+func (s *LocalStorage) VerifyUploadSignature(key, expires, signature string) error {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	return s.VerifySignature(uploadSigningKey(cleaned), expires, signature)
+}
+
+// uploadSigningKey membedakan signature upload dari signature download untuk key yang sama
+func uploadSigningKey(key string) string {
+	return "upload:" + key
+}
+
+func (s *LocalStorage) KeyFromURL(rawURL string) (string, bool) {
+	if s.cfg.BaseURL == "" {
+		return "", false
+	}
+	prefix := s.cfg.BaseURL + "/"
+	if !strings.HasPrefix(rawURL, prefix) {
+		return "", false
+	}
+	key, err := cleanKey(strings.SplitN(strings.TrimPrefix(rawURL, prefix), "?", 2)[0])
+	return key, err == nil
+}
+
+func (s *LocalStorage) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, []byte(s.cfg.SigningSecret))
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config konfigurasi driver S3-compatible (AWS S3, MinIO, Cloudflare R2, dll)
+type S3Config struct {
+	Endpoint  string // Contoh: s3.amazonaws.com atau localhost:9000 (MinIO)
+	AccessKey string
+	SecretKey string
+	Bucket    string
+	Region    string
+	UseSSL    bool
+	PublicURL string // Prefix URL publik object, default: {scheme}://{endpoint}/{bucket}
+}
+
+// S3Storage menyimpan file di bucket S3-compatible
+type S3Storage struct {
+	client    *minio.Client
+	bucket    string
+	publicURL string
+}
+
+func NewS3Storage(cfg S3Config) (*S3Storage, error) {
+	if cfg.Endpoint == "" || cfg.AccessKey == "" || cfg.SecretKey == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("S3 credentials belum diatur di .env (S3_ENDPOINT, S3_ACCESS_KEY, S3_SECRET_KEY, S3_BUCKET)")
+	}
+
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Gagal inisialisasi S3 client: %v", err)
+	}
+
+	publicURL := strings.TrimRight(cfg.PublicURL, "/")
+	if publicURL == "" {
+		scheme := "http"
+		if cfg.UseSSL {
+			scheme = "https"
+		}
+		publicURL = fmt.Sprintf("%s://%s/%s", scheme, cfg.Endpoint, cfg.Bucket)
+	}
+
+	return &S3Storage{client: client, bucket: cfg.Bucket, publicURL: publicURL}, nil
+}
+
+func (s *S3Storage) Name() string { return DriverS3 }
+
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (string, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	_, err = s.client.PutObject(ctx, s.bucket, cleaned, r, size, minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return "", fmt.Errorf("Gagal upload ke S3: %v", err)
+	}
+	return s.URL(cleaned), nil
+}
+
+func (s *S3Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.client.GetObject(ctx, s.bucket, cleaned, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("Gagal membaca file dari S3: %v", err)
+	}
+	// GetObject baru request saat dibaca, Stat dipakai untuk memastikan object ada
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		if minio.ToErrorResponse(err).Code == minio.NoSuchKey {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("Gagal membaca file dari S3: %v", err)
+	}
+	return obj, nil
+}
+
+func (s *S3Storage) URL(key string) string {
+	cleaned, _ := cleanKey(key)
+	return s.publicURL + "/" + cleaned
+}
+
+// PresignUpload membuat presigned POST policy dengan batas ukuran dan content type
+func (s *S3Storage) PresignUpload(ctx context.Context, key, contentType string, maxSize int64, expiry time.Duration) (*UploadTarget, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(expiry)
+
+	policy := minio.NewPostPolicy()
+	if err := policy.SetBucket(s.bucket); err != nil {
+		return nil, err
+	}
+	if err := policy.SetKey(cleaned); err != nil {
+		return nil, err
+	}
+	if err := policy.SetExpires(expiresAt); err != nil {
+		return nil, err
+	}
+	if err := policy.SetContentType(contentType); err != nil {
+		return nil, err
+	}
+	if err := policy.SetContentLengthRange(1, maxSize); err != nil {
+		return nil, err
+	}
+
+	u, fields, err := s.client.PresignedPostPolicy(ctx, policy)
+	if err != nil {
+		return nil, fmt.Errorf("Gagal membuat signed upload: %v", err)
+	}
+	return &UploadTarget{
+		Method:    "POST",
+		URL:       u.String(),
+		Fields:    fields,
+		FileField: "file",
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	if err := s.client.RemoveObject(ctx, s.bucket, cleaned, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("Gagal hapus dari S3: %v", err)
+	}
+	return nil
+}
+
+func (s *S3Storage) Exists(ctx context.Context, key string) (bool, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return false, err
+	}
+	_, err = s.client.StatObject(ctx, s.bucket, cleaned, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == minio.NoSuchKey {
+			return false, nil
+		}
+		return false, fmt.Errorf("Gagal cek file di S3: %v", err)
+	}
+	return true, nil
+}
+
+func (s *S3Storage) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, cleaned, expiry, url.Values{})
+	if err != nil {
+		return "", fmt.Errorf("Gagal membuat signed URL: %v", err)
+	}
+	return u.String(), nil
+}
+
+func (s *S3Storage) KeyFromURL(rawURL string) (string, bool) {
+	prefix := s.publicURL + "/"
+	if !strings.HasPrefix(rawURL, prefix) {
+		return "", false
+	}
+	key, err := cleanKey(strings.SplitN(strings.TrimPrefix(rawURL, prefix), "?", 2)[0])
+	return key, err == nil
+}
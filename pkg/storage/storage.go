@@ -0,0 +1,176 @@
+// Package storage menyediakan abstraksi penyimpanan file (foto, dokumen)
+// dengan driver Cloudinary, local disk dan S3-compatible (AWS S3, MinIO, R2, dll).
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrNotFound dikembalikan kalau object tidak ada di storage
+var ErrNotFound = errors.New("file tidak ditemukan di storage")
+
+// ErrExpiryUnsupported dikembalikan SignedURL driver yang tidak bisa membuat link kadaluarsa (Cloudinary)
+var ErrExpiryUnsupported = errors.New("driver storage tidak mendukung link yang kadaluarsa")
+
+// Storage adalah kontrak yang harus dipenuhi setiap driver penyimpanan.
+// Key adalah path relatif object, contoh: "property-photos/20260101150405_rumah.jpg".
+type Storage interface {
+	// Put menyimpan isi r dengan key tertentu dan mengembalikan URL publiknya
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (string, error)
+	// Open membaca isi object, caller wajib menutup reader
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete menghapus object, tidak error kalau object sudah tidak ada
+	Delete(ctx context.Context, key string) error
+	// Exists mengecek apakah object ada
+	Exists(ctx context.Context, key string) (bool, error)
+	// SignedURL membuat URL sementara untuk mengakses object, ErrExpiryUnsupported kalau driver
+	// tidak bisa membatasi waktu akses
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+	// URL mengembalikan URL publik object untuk key tertentu
+	URL(key string) string
+	// KeyFromURL mengembalikan key dari URL yang pernah dikembalikan Put
+	KeyFromURL(url string) (string, bool)
+	// Name nama driver, untuk logging
+	Name() string
+}
+
+// Nama driver untuk STORAGE_DRIVER
+const (
+	DriverCloudinary = "cloudinary"
+	DriverLocal      = "local"
+	DriverS3         = "s3"
+)
+
+// NewFromEnv membuat storage sesuai STORAGE_DRIVER.
+// Kalau STORAGE_DRIVER kosong: pakai Cloudinary kalau credentials ada, selain itu local disk.
+func NewFromEnv() (Storage, error) {
+	driver := strings.ToLower(os.Getenv("STORAGE_DRIVER"))
+	if driver == "" {
+		driver = DriverLocal
+		if os.Getenv("CLOUDINARY_CLOUD_NAME") != "" && os.Getenv("CLOUDINARY_API_KEY") != "" && os.Getenv("CLOUDINARY_API_SECRET") != "" {
+			driver = DriverCloudinary
+		}
+	}
+
+	switch driver {
+	case DriverCloudinary:
+		return NewCloudinaryStorage(os.Getenv("CLOUDINARY_CLOUD_NAME"), os.Getenv("CLOUDINARY_API_KEY"), os.Getenv("CLOUDINARY_API_SECRET"))
+	case DriverLocal:
+		return NewLocalStorage(LocalConfig{
+			Dir:           envOrDefault("LOCAL_STORAGE_DIR", "./uploads"),
+			BaseURL:       envOrDefault("LOCAL_STORAGE_BASE_URL", "/uploads"),
+			SignedBaseURL: envOrDefault("LOCAL_STORAGE_SIGNED_URL", "/storage"),
+			SigningSecret: signingSecret(),
+		})
+	case DriverS3:
+		return NewS3Storage(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    os.Getenv("S3_REGION"),
+			UseSSL:    os.Getenv("S3_USE_SSL") != "false",
+			PublicURL: os.Getenv("S3_PUBLIC_URL"),
+		})
+	default:
+		return nil, fmt.Errorf("STORAGE_DRIVER %q tidak dikenal, gunakan: cloudinary, local, s3", driver)
+	}
+}
+
+// NewPrivateFromEnv membuat storage private untuk file yang tidak boleh punya URL publik
+// (foto asli tanpa watermark, dokumen legal). File hanya bisa diakses lewat SignedURL.
+// PRIVATE_STORAGE_DRIVER: local (folder terpisah yang tidak di-serve publik) atau s3 (S3_PRIVATE_BUCKET,
+// bucket tanpa akses publik). Kosong: s3 kalau STORAGE_DRIVER=s3 dan S3_PRIVATE_BUCKET diisi, selain itu local.
+// Cloudinary tidak dipakai karena URL delivery-nya selalu publik.
+func NewPrivateFromEnv() (Storage, error) {
+	driver := strings.ToLower(os.Getenv("PRIVATE_STORAGE_DRIVER"))
+	if driver == "" {
+		driver = DriverLocal
+		if strings.ToLower(os.Getenv("STORAGE_DRIVER")) == DriverS3 && os.Getenv("S3_PRIVATE_BUCKET") != "" {
+			driver = DriverS3
+		}
+	}
+
+	switch driver {
+	case DriverLocal:
+		return NewLocalStorage(LocalConfig{
+			Dir:           envOrDefault("LOCAL_PRIVATE_STORAGE_DIR", "./private"),
+			SignedBaseURL: envOrDefault("LOCAL_PRIVATE_SIGNED_URL", "/private"),
+			SigningSecret: signingSecret(),
+		})
+	case DriverS3:
+		if os.Getenv("S3_PRIVATE_BUCKET") == "" {
+			return nil, fmt.Errorf("S3_PRIVATE_BUCKET wajib diisi untuk PRIVATE_STORAGE_DRIVER=s3")
+		}
+		return NewS3Storage(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			Bucket:    os.Getenv("S3_PRIVATE_BUCKET"),
+			Region:    os.Getenv("S3_REGION"),
+			UseSSL:    os.Getenv("S3_USE_SSL") != "false",
+		})
+	default:
+		return nil, fmt.Errorf("PRIVATE_STORAGE_DRIVER %q tidak dikenal, gunakan: local, s3", driver)
+	}
+}
+
+var unsafeKeyChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// NewKey membuat key unik dari folder dan nama file asli,
+// contoh: NewKey("property-photos", "Rumah Depan.JPG") -> "property-photos/20260101150405_1a2b3c4d_rumah-depan.jpg"
+func NewKey(folder, filename string) string {
+	ext := strings.ToLower(path.Ext(filename))
+	base := strings.TrimSuffix(path.Base(strings.ReplaceAll(filename, "\\", "/")), path.Ext(filename))
+	base = strings.Trim(unsafeKeyChars.ReplaceAllString(strings.ToLower(base), "-"), "-.")
+	if base == "" {
+		base = "file"
+	}
+	if len(base) > 80 {
+		base = base[:80]
+	}
+
+	// Suffix acak supaya upload bersamaan dengan nama sama tidak saling menimpa
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+
+	name := fmt.Sprintf("%s_%s_%s%s", time.Now().Format("20060102150405"), hex.EncodeToString(suffix), base, ext)
+	return path.Join(folder, name)
+}
+
+// cleanKey mencegah key keluar dari root storage (path traversal)
+func cleanKey(key string) (string, error) {
+	cleaned := strings.TrimPrefix(path.Clean("/"+key), "/")
+	if cleaned == "" || cleaned == "." {
+		return "", fmt.Errorf("key storage tidak valid: %q", key)
+	}
+	return cleaned, nil
+}
+
+func envOrDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// signingSecret secret untuk signed URL local storage, fallback ke JWT_SECRET
+func signingSecret() string {
+	if secret := os.Getenv("STORAGE_SIGNING_SECRET"); secret != "" {
+		return secret
+	}
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		return secret
+	}
+	return "default-secret-key-change-in-production"
+}
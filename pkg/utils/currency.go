@@ -0,0 +1,33 @@
+package utils
+
+import "math"
+
+// DefaultCurrency adalah mata uang default listing
+const DefaultCurrency = "IDR"
+
+// ConvertPrice mengkonversi amount dari mata uang from ke to memakai kurs terhadap Rupiah.
+// Return false kalau kurs salah satu mata uang tidak tersedia.
+func ConvertPrice(amount int64, from, to string, idrPerUnit map[string]float64) (int64, bool) {
+	if from == to {
+		return amount, true
+	}
+
+	fromRate, ok := rateOf(from, idrPerUnit)
+	if !ok {
+		return 0, false
+	}
+	toRate, ok := rateOf(to, idrPerUnit)
+	if !ok {
+		return 0, false
+	}
+
+	return int64(math.Round(float64(amount) * fromRate / toRate)), true
+}
+
+func rateOf(currency string, idrPerUnit map[string]float64) (float64, bool) {
+	if currency == DefaultCurrency {
+		return 1, true
+	}
+	rate, ok := idrPerUnit[currency]
+	return rate, ok && rate > 0
+}
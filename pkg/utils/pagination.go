@@ -1,7 +1,10 @@
 package utils
 
 import (
+	"project-zero/pkg/rupiah"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,8 +22,23 @@ type QueryParams struct {
 	Bedrooms    int
 	Bathrooms   int
 	Certificate string
+	Verified    bool // ?verified=true untuk listing dengan badge "verified legal"
 	Location    string
 	Title       string
+
+	MinMonthlyPrice int64  // Filter harga sewa per bulan (WTR)
+	MaxMonthlyPrice int64  // Filter harga sewa per bulan (WTR)
+	PricePeriod     string // daily, monthly, yearly
+
+	PriceReducedSince time.Time // Listing yang harganya turun sejak tanggal ini
+
+	Currency   string   // Filter mata uang listing
+	Currencies []string // Mata uang tampilan untuk converted_prices, contoh: ?currencies=USD,SGD
+	PriceWords bool     // ?terbilang=true untuk ikut menampilkan harga dalam kata-kata
+
+	Amenities     []string // Slug amenity, contoh: ?amenities=pool,carport
+	AmenitiesMode string   // "all" (default) atau "any"
+	WithFacets    bool     // ?facets=true untuk ikut hitung facet counts
 }
 
 // PaginationMetadata menyimpan informasi pagination
@@ -31,11 +49,19 @@ type PaginationMetadata struct {
 	TotalPages int   `json:"total_pages"`
 }
 
+// FacetCount menyimpan jumlah data untuk satu nilai facet
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int64  `json:"count"`
+}
+
 // PaginatedResponse adalah wrapper untuk response yang di-paginate
 type PaginatedResponse struct {
-	Data       interface{}            `json:"data"`
-	Pagination PaginationMetadata     `json:"pagination"`
-	Filters    map[string]interface{} `json:"filters,omitempty"`
+	Data       interface{}             `json:"data"`
+	Pagination PaginationMetadata      `json:"pagination"`
+	Filters    map[string]interface{}  `json:"filters,omitempty"`
+	Facets     map[string][]FacetCount `json:"facets,omitempty"`
 }
 
 // ParseQueryParams parsing query parameters dari Gin context
@@ -48,17 +74,7 @@ func ParseQueryParams(c *gin.Context) QueryParams {
 	}
 
 	// Parse pagination
-	if page := c.DefaultQuery("page", "1"); page != "" {
-		if p, err := strconv.Atoi(page); err == nil && p > 0 {
-			params.Page = p
-		}
-	}
-
-	if limit := c.DefaultQuery("limit", "10"); limit != "" {
-		if l, err := strconv.Atoi(limit); err == nil && l > 0 && l <= 100 {
-			params.Limit = l
-		}
-	}
+	params.Page, params.Limit = ParsePagination(c)
 
 	// Parse sorting
 	if sortBy := c.Query("sort_by"); sortBy != "" {
@@ -66,6 +82,7 @@ func ParseQueryParams(c *gin.Context) QueryParams {
 		validSortFields := map[string]bool{
 			"id": true, "created_at": true, "price": true,
 			"title": true, "bedrooms": true, "bathrooms": true,
+			"monthly_price": true,
 		}
 		if validSortFields[sortBy] {
 			params.SortBy = sortBy
@@ -78,17 +95,48 @@ func ParseQueryParams(c *gin.Context) QueryParams {
 
 	// Parse filtering
 	if minPrice := c.Query("min_price"); minPrice != "" {
-		if p, err := strconv.ParseInt(minPrice, 10, 64); err == nil && p >= 0 {
+		if p, err := rupiah.Parse(minPrice); err == nil && p >= 0 {
 			params.MinPrice = p
 		}
 	}
 
 	if maxPrice := c.Query("max_price"); maxPrice != "" {
-		if p, err := strconv.ParseInt(maxPrice, 10, 64); err == nil && p > 0 {
+		if p, err := rupiah.Parse(maxPrice); err == nil && p > 0 {
 			params.MaxPrice = p
 		}
 	}
 
+	if minMonthly := c.Query("min_monthly_price"); minMonthly != "" {
+		if p, err := rupiah.Parse(minMonthly); err == nil && p >= 0 {
+			params.MinMonthlyPrice = p
+		}
+	}
+
+	if maxMonthly := c.Query("max_monthly_price"); maxMonthly != "" {
+		if p, err := rupiah.Parse(maxMonthly); err == nil && p > 0 {
+			params.MaxMonthlyPrice = p
+		}
+	}
+
+	if period := c.Query("price_period"); period == "daily" || period == "monthly" || period == "yearly" {
+		params.PricePeriod = period
+	}
+
+	if since := c.Query("price_reduced_since"); since != "" {
+		if t, err := time.Parse("2006-01-02", since); err == nil {
+			params.PriceReducedSince = t
+		} else if t, err := time.Parse(time.RFC3339, since); err == nil {
+			params.PriceReducedSince = t
+		}
+	}
+
+	if currency := strings.ToUpper(c.Query("currency")); len(currency) == 3 {
+		params.Currency = currency
+	}
+
+	params.Currencies = ParseCurrencies(c.Query("currencies"))
+	params.PriceWords = ParseBool(c.Query("terbilang"))
+
 	if listing := c.Query("listing_type"); listing == "WTS" || listing == "WTR" {
 		params.ListingType = listing
 	}
@@ -109,6 +157,8 @@ func ParseQueryParams(c *gin.Context) QueryParams {
 		params.Certificate = cert
 	}
 
+	params.Verified = ParseBool(c.Query("verified"))
+
 	if location := c.Query("location"); location != "" {
 		params.Location = location
 	}
@@ -117,9 +167,55 @@ func ParseQueryParams(c *gin.Context) QueryParams {
 		params.Title = title
 	}
 
+	// Slug dibuat unik supaya mode "all" (HAVING COUNT = jumlah slug) tetap benar untuk ?amenities=pool,pool
+	if amenities := c.Query("amenities"); amenities != "" {
+		seen := make(map[string]bool)
+		for _, slug := range strings.Split(amenities, ",") {
+			if slug = strings.ToLower(strings.TrimSpace(slug)); slug != "" && !seen[slug] {
+				seen[slug] = true
+				params.Amenities = append(params.Amenities, slug)
+			}
+		}
+	}
+
+	params.AmenitiesMode = "all"
+	if mode := c.Query("amenities_mode"); mode == "any" {
+		params.AmenitiesMode = mode
+	}
+
+	params.WithFacets = ParseBool(c.Query("facets"))
+
 	return params
 }
 
+// ParsePagination parsing ?page= dan ?limit= (default 1 dan 10, limit maksimal 100)
+func ParsePagination(c *gin.Context) (page, limit int) {
+	page, limit = 1, 10
+	if p, err := strconv.Atoi(c.DefaultQuery("page", "1")); err == nil && p > 0 {
+		page = p
+	}
+	if l, err := strconv.Atoi(c.DefaultQuery("limit", "10")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	return page, limit
+}
+
+// ParseCurrencies parsing daftar kode mata uang "USD,sgd" menjadi ["USD", "SGD"]
+func ParseCurrencies(raw string) []string {
+	var currencies []string
+	for _, code := range strings.Split(raw, ",") {
+		if code = strings.ToUpper(strings.TrimSpace(code)); len(code) == 3 {
+			currencies = append(currencies, code)
+		}
+	}
+	return currencies
+}
+
+// ParseBool membaca flag query seperti ?facets=true atau ?terbilang=1
+func ParseBool(raw string) bool {
+	return raw == "true" || raw == "1"
+}
+
 // CalculateOffset menghitung offset untuk LIMIT/OFFSET query
 func CalculateOffset(page, limit int) int {
 	return (page - 1) * limit
@@ -143,6 +239,21 @@ func BuildFiltersMap(params QueryParams) map[string]interface{} {
 	if params.MaxPrice > 0 {
 		filters["max_price"] = params.MaxPrice
 	}
+	if params.MinMonthlyPrice > 0 {
+		filters["min_monthly_price"] = params.MinMonthlyPrice
+	}
+	if params.MaxMonthlyPrice > 0 {
+		filters["max_monthly_price"] = params.MaxMonthlyPrice
+	}
+	if params.PricePeriod != "" {
+		filters["price_period"] = params.PricePeriod
+	}
+	if !params.PriceReducedSince.IsZero() {
+		filters["price_reduced_since"] = params.PriceReducedSince.Format(time.RFC3339)
+	}
+	if params.Currency != "" {
+		filters["currency"] = params.Currency
+	}
 	if params.ListingType != "" {
 		filters["listing_type"] = params.ListingType
 	}
@@ -155,12 +266,19 @@ func BuildFiltersMap(params QueryParams) map[string]interface{} {
 	if params.Certificate != "" {
 		filters["certificate"] = params.Certificate
 	}
+	if params.Verified {
+		filters["verified"] = true
+	}
 	if params.Location != "" {
 		filters["location"] = params.Location
 	}
 	if params.Title != "" {
 		filters["title"] = params.Title
 	}
+	if len(params.Amenities) > 0 {
+		filters["amenities"] = params.Amenities
+		filters["amenities_mode"] = params.AmenitiesMode
+	}
 
 	return filters
 }
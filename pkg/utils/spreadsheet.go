@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ErrTooManyRows file punya lebih banyak baris data dari batas yang diminta
+var ErrTooManyRows = errors.New("Terlalu banyak baris")
+
+// maxXLSXUnzipSize batas total isi XLSX setelah di-unzip, mencegah zip bomb
+const maxXLSXUnzipSize = 200 * 1024 * 1024
+
+// ReadSpreadsheet membaca file CSV atau XLSX (sheet pertama) menjadi baris-baris string.
+// Baris pertama biasanya header. Baris dibaca satu per satu dan berhenti dengan ErrTooManyRows
+// begitu jumlah baris data (di luar header dan baris kosong) melebihi maxRows.
+func ReadSpreadsheet(file *multipart.FileHeader, maxRows int) ([][]string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("Gagal membuka file: %v", err)
+	}
+	defer src.Close()
+
+	switch strings.ToLower(filepath.Ext(file.Filename)) {
+	case ".csv":
+		return readCSV(src, maxRows)
+	case ".xlsx":
+		return readXLSX(src, maxRows)
+	default:
+		return nil, fmt.Errorf("Tipe file tidak didukung, gunakan: csv, xlsx")
+	}
+}
+
+func readCSV(src multipart.File, maxRows int) ([][]string, error) {
+	reader := bufio.NewReader(src)
+
+	// Buang UTF-8 BOM dari export Excel
+	if bom, err := reader.Peek(3); err == nil && bytes.Equal(bom, []byte{0xEF, 0xBB, 0xBF}) {
+		reader.Discard(3)
+	}
+
+	// Excel dengan locale Indonesia menyimpan CSV dengan pemisah titik koma
+	delimiter := ','
+	head, _ := reader.Peek(4096)
+	firstLine := string(head)
+	if i := strings.IndexByte(firstLine, '\n'); i >= 0 {
+		firstLine = firstLine[:i]
+	}
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		delimiter = ';'
+	}
+
+	r := csv.NewReader(reader)
+	r.Comma = delimiter
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	var limiter rowLimiter
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("File CSV tidak valid: %v", err)
+		}
+		if err := limiter.add(row, maxRows); err != nil {
+			return nil, err
+		}
+	}
+	return limiter.result(), nil
+}
+
+func readXLSX(src multipart.File, maxRows int) ([][]string, error) {
+	f, err := excelize.OpenReader(src, excelize.Options{UnzipSizeLimit: maxXLSXUnzipSize})
+	if err != nil {
+		return nil, fmt.Errorf("File XLSX tidak valid: %v", err)
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("File XLSX tidak punya sheet")
+	}
+
+	// Rows membaca XML sheet secara streaming, tidak seperti GetRows yang memuat semua baris dulu
+	iter, err := f.Rows(sheets[0])
+	if err != nil {
+		return nil, fmt.Errorf("Gagal membaca sheet %s: %v", sheets[0], err)
+	}
+	defer iter.Close()
+
+	var limiter rowLimiter
+	for iter.Next() {
+		row, err := iter.Columns()
+		if err != nil {
+			return nil, fmt.Errorf("Gagal membaca sheet %s: %v", sheets[0], err)
+		}
+		if err := limiter.add(row, maxRows); err != nil {
+			return nil, err
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("Gagal membaca sheet %s: %v", sheets[0], err)
+	}
+	return limiter.result(), nil
+}
+
+// rowLimiter mengumpulkan baris dan menghitung baris data yang tidak kosong
+type rowLimiter struct {
+	rows     [][]string
+	dataRows int
+}
+
+func (l *rowLimiter) add(row []string, maxRows int) error {
+	if !isBlank(row) && len(l.rows) > 0 {
+		l.dataRows++
+		if l.dataRows > maxRows {
+			return ErrTooManyRows
+		}
+	}
+	l.rows = append(l.rows, row)
+	return nil
+}
+
+// result membuang baris kosong di akhir file (sisa format Excel)
+func (l *rowLimiter) result() [][]string {
+	rows := l.rows
+	for len(rows) > 0 && isBlank(rows[len(rows)-1]) {
+		rows = rows[:len(rows)-1]
+	}
+	return rows
+}
+
+func isBlank(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// SafeCell memberi awalan ' pada teks yang diawali =, +, - atau @ supaya tidak dijalankan
+// sebagai formula saat file export dibuka di aplikasi spreadsheet (CSV/formula injection)
+func SafeCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// UnsafeCell kebalikan SafeCell untuk file hasil export yang diimport ulang
+func UnsafeCell(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@", rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
@@ -0,0 +1,149 @@
+// Package video membaca info video upload (durasi, resolusi), membuang metadata dan mengambil
+// poster frame lewat ffprobe/ffmpeg. Binary-nya harus tersedia di PATH atau diatur lewat
+// FFPROBE_PATH dan FFMPEG_PATH.
+package video
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ErrUnavailable ffprobe/ffmpeg tidak ditemukan di server
+var ErrUnavailable = errors.New("ffprobe/ffmpeg tidak tersedia di server")
+
+// Info hasil probe satu file video
+type Info struct {
+	Duration float64 // Detik
+	Width    int
+	Height   int
+	Codec    string
+}
+
+// Tool menjalankan ffprobe dan ffmpeg
+type Tool struct {
+	FFprobe string
+	FFmpeg  string
+}
+
+// NewToolFromEnv membuat Tool dengan path binary dari FFPROBE_PATH dan FFMPEG_PATH (default dari PATH)
+func NewToolFromEnv() *Tool {
+	tool := &Tool{FFprobe: "ffprobe", FFmpeg: "ffmpeg"}
+	if path := os.Getenv("FFPROBE_PATH"); path != "" {
+		tool.FFprobe = path
+	}
+	if path := os.Getenv("FFMPEG_PATH"); path != "" {
+		tool.FFmpeg = path
+	}
+	return tool
+}
+
+// inputArgs opsi input ffmpeg/ffprobe: format container dikunci ke hasil deteksi isi file dan hanya
+// protokol file yang diizinkan, supaya file upload tidak bisa memicu demuxer lain (HLS, concat)
+// yang membaca file lokal atau URL jaringan
+func inputArgs(format string) []string {
+	return []string{"-protocol_whitelist", "file", "-f", format}
+}
+
+// Probe membaca durasi dan resolusi stream video pertama
+func (t *Tool) Probe(ctx context.Context, path, format string) (*Info, error) {
+	args := append([]string{"-v", "error"}, inputArgs(format)...)
+	out, err := t.run(ctx, t.FFprobe, append(args,
+		"-select_streams", "v:0",
+		"-show_entries", "stream=codec_name,width,height:format=duration",
+		"-of", "json",
+		path,
+	)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var probe struct {
+		Streams []struct {
+			CodecName string `json:"codec_name"`
+			Width     int    `json:"width"`
+			Height    int    `json:"height"`
+		} `json:"streams"`
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(out, &probe); err != nil {
+		return nil, fmt.Errorf("Output ffprobe tidak valid: %v", err)
+	}
+	if len(probe.Streams) == 0 {
+		return nil, errors.New("File tidak punya stream video")
+	}
+
+	duration, err := strconv.ParseFloat(probe.Format.Duration, 64)
+	if err != nil {
+		return nil, errors.New("Durasi video tidak bisa dibaca")
+	}
+	stream := probe.Streams[0]
+	return &Info{Duration: duration, Width: stream.Width, Height: stream.Height, Codec: stream.CodecName}, nil
+}
+
+// StripMetadata menyalin video ke dst tanpa metadata container (lokasi GPS, model HP, dll)
+// dan chapter. Stream disalin apa adanya tanpa encode ulang. MP4 ditulis dengan faststart
+// supaya bisa langsung diputar sebelum selesai diunduh.
+func (t *Tool) StripMetadata(ctx context.Context, src, dst, format string) error {
+	args := []string{
+		"-v", "error", "-y",
+	}
+	args = append(args, inputArgs(format)...)
+	args = append(args,
+		"-i", src,
+		"-map", "0:v", "-map", "0:a?",
+		"-map_metadata", "-1", "-map_chapters", "-1",
+		"-c", "copy",
+	)
+	if format == "mp4" {
+		args = append(args, "-movflags", "+faststart")
+	}
+	args = append(args, "-f", format, dst)
+	_, err := t.run(ctx, t.FFmpeg, args...)
+	return err
+}
+
+// PosterFrame mengambil satu frame pada detik ke-at sebagai PNG
+func (t *Tool) PosterFrame(ctx context.Context, path, format string, at float64) ([]byte, error) {
+	args := append([]string{"-v", "error"}, inputArgs(format)...)
+	return t.run(ctx, t.FFmpeg, append(args,
+		"-ss", strconv.FormatFloat(at, 'f', 3, 64),
+		"-i", path,
+		"-frames:v", "1",
+		"-f", "image2pipe", "-c:v", "png",
+		"-",
+	)...)
+}
+
+// PosterTime detik frame poster: detik pertama, atau tengah video kalau video lebih pendek dari 2 detik
+func PosterTime(duration float64) float64 {
+	if duration < 2 {
+		return duration / 2
+	}
+	return 1
+}
+
+func (t *Tool) run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) || errors.Is(err, os.ErrNotExist) {
+			return nil, ErrUnavailable
+		}
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("Proses video terlalu lama: %v", ctx.Err())
+		}
+		return nil, fmt.Errorf("%s gagal: %s", name, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}